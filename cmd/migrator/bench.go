@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"migration-go/internal/migrate"
+)
+
+// runBench executa as estratégias em sequência e imprime uma tabela comparativa
+func runBench(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	list := fs.String("strategies", strings.Join(migrate.Strategies(), ","),
+		"estratégias a executar, separadas por vírgula")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	// Cada estratégia parte do destino vazio para que os tempos sejam comparáveis
+	cfg.App.Truncate = true
+	cfg.App.Resume = false
+
+	var results []*migrate.Stats
+	for _, name := range strings.Split(*list, ",") {
+		strategy, err := migrate.Lookup(strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("\n=== Estratégia %s ===\n", strategy.Name())
+		stats, err := migrate.Run(ctx, cfg, strategy)
+		if err != nil {
+			if stats != nil {
+				fmt.Println(stats)
+			}
+			return err
+		}
+		results = append(results, stats)
+	}
+
+	fmt.Println("\n=== Resultado do benchmark ===")
+	fmt.Printf("%-20s %12s %12s %8s %15s\n", "Estratégia", "Lidos", "Gravados", "Falhas", "Duração")
+	for _, s := range results {
+		fmt.Printf("%-20s %12d %12d %8d %15s\n", s.Strategy, s.Read, s.Written, s.Failed, s.Duration)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"migration-go/internal/migrate"
+)
+
+// runCutover copia um snapshot consistente e depois replica as alterações a
+// partir do mesmo instante, até Ctrl+C
+func runCutover(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("cutover", flag.ExitOnError)
+	strategyName := fs.String("strategy", migrate.StreamWorkers.Name(),
+		"estratégia da cópia inicial ("+strings.Join(migrate.Strategies(), ", ")+")")
+	preImages := fs.Bool("enable-pre-images", false, "altera a coleção de origem (collMod) para guardar pre-images, necessárias para aplicar deletes")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	strategy, err := migrate.Lookup(*strategyName)
+	if err != nil {
+		return err
+	}
+
+	stats, syncStats, err := migrate.Cutover(ctx, cfg, strategy, migrate.SyncOptions{EnablePreImages: *preImages})
+	if stats != nil {
+		fmt.Println(stats)
+	}
+	if syncStats != nil {
+		fmt.Println(syncStats)
+	}
+	return err
+}
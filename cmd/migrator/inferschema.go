@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"migration-go/internal/database"
+	"migration-go/internal/schema"
+)
+
+// runInferSchema amostra a coleção de origem e propõe o DDL da tabela de destino
+func runInferSchema(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("infer-schema", flag.ExitOnError)
+	sample := fs.Int("sample", 1000, "quantidade de documentos amostrados")
+	table := fs.String("table", "", "nome da tabela proposta (padrão: nome da coleção)")
+	output := fs.String("output", "", "grava o DDL proposto neste arquivo")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if *table == "" {
+		*table = cfg.MongoDB.Collection
+	}
+
+	mongoManager := database.NewMongoManager(&cfg.MongoDB)
+	if err := mongoManager.Connect(ctx); err != nil {
+		return err
+	}
+	defer mongoManager.Disconnect(context.Background())
+
+	s, err := schema.Infer(ctx, mongoManager.GetCollection(), *sample)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d documentos amostrados de %s, %d campos encontrados.\n\n", s.Sampled, s.Collection, len(s.Fields))
+
+	ddl, err := s.DDL(*table)
+	if err != nil {
+		return fmt.Errorf("não foi possível propor a tabela: %w", err)
+	}
+	fmt.Println(ddl)
+
+	if conflicts := s.Conflicts(); len(conflicts) > 0 {
+		fmt.Printf("%d campos com tipos conflitantes (tipo BSON=ocorrências -> tipo proposto):\n", len(conflicts))
+		for _, c := range conflicts {
+			fmt.Printf("  %s\n", c)
+		}
+	} else {
+		fmt.Println("Nenhum conflito de tipos na amostra.")
+	}
+
+	if *output != "" {
+		if err := os.WriteFile(*output, []byte(ddl), 0o644); err != nil {
+			return fmt.Errorf("erro ao gravar o DDL: %w", err)
+		}
+		fmt.Printf("DDL gravado em %s\n", *output)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"migration-go/internal/job"
+	"migration-go/internal/migrate"
+)
+
+// runJob executa as etapas de um arquivo de job, em paralelo quando as
+// dependências permitem
+func runJob(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("job", flag.ExitOnError)
+	file := fs.String("file", "", "arquivo JSON com as etapas coleção -> tabela e suas dependências (obrigatório)")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("informe o arquivo do job com --file")
+	}
+
+	j, err := job.Load(*file)
+	if err != nil {
+		return err
+	}
+
+	stats, err := migrate.RunJob(ctx, cfg, j)
+	if stats != nil {
+		fmt.Println(stats)
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+// Command migrator reúne em um único binário o seed, as estratégias de migração
+// MongoDB -> PostgreSQL e as ferramentas de apoio (verificação, benchmark e
+// teste de memória).
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"migration-go/internal/config"
+)
+
+// command descreve um subcomando do migrator
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"seed", "popula o MongoDB com produtos de teste", runSeed},
+	{"migrate", "migra os produtos do MongoDB para o PostgreSQL", runMigrate},
+	{"job", "migra várias coleções em paralelo, respeitando as dependências", runJob},
+	{"cutover", "copia um snapshot consistente e replica as alterações desde ele", runCutover},
+	{"sync", "replica continuamente as alterações do MongoDB via change streams", runSync},
+	{"reverse", "copia os produtos do PostgreSQL de volta para o MongoDB (rollback)", runReverse},
+	{"replay", "regrava no PostgreSQL os documentos da dead-letter", runReplay},
+	{"verify", "compara a origem no MongoDB com o destino no PostgreSQL", runVerify},
+	{"repair", "corrige no PostgreSQL as divergências encontradas pelo verify", runRepair},
+	{"infer-schema", "amostra a coleção e propõe o CREATE TABLE de destino", runInferSchema},
+	{"bench", "executa todas as estratégias de migração e compara os tempos", runBench},
+	{"memtest", "teste de limite de memória (pode consumir toda a RAM!)", runMemtest},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	// Ctrl+C ou SIGTERM cancelam o contexto: a leitura para, os workers
+	// confirmam o que já receberam e o resumo é impresso antes de sair
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if err := cmd.run(ctx, os.Args[2:]); err != nil {
+			stop()
+			log.Fatalf("Erro no comando %s: %v", name, err)
+		}
+		return
+	}
+
+	if name != "help" && name != "-h" && name != "--help" {
+		fmt.Fprintf(os.Stderr, "Comando desconhecido: %s\n\n", name)
+	}
+	usage()
+	os.Exit(2)
+}
+
+// usage imprime a lista de subcomandos disponíveis
+func usage() {
+	fmt.Fprintln(os.Stderr, "Uso: migrator <comando> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Comandos:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-13s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Use 'migrator <comando> -h' para ver as flags de cada comando.")
+}
+
+// loadConfig carrega a configuração do ambiente e aplica as flags da linha de
+// comando por cima. Flags próprias do subcomando devem ser registradas em fs
+// antes da chamada.
+func loadConfig(fs *flag.FlagSet, args []string) (*config.Config, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar configuração: %w", err)
+	}
+
+	cfg.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"runtime"
+	"time"
+
+	"migration-go/internal/models"
+)
+
+// runMemtest aloca produtos grandes em memória até o processo estourar a RAM
+func runMemtest(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("memtest", flag.ExitOnError)
+	// 130 milhões de registros garantem o estouro em 32GB de RAM
+	records := fs.Int("records", 130_000_000, "quantidade de registros a alocar")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	log.Println("Iniciando teste de quebra de memória...")
+	log.Println("Abra o Monitor de Atividade (macOS), Gerenciador de Tarefas (Windows) ou htop (Linux) para observar o consumo de RAM.")
+
+	// Slice que vai crescer até quebrar a memória
+	var memoryHog []models.LargeProduct
+
+	// Goroutine para imprimir estatísticas de memória
+	go func() {
+		for {
+			var m runtime.MemStats
+			runtime.ReadMemStats(&m)
+			log.Printf("Memória Alocada: %d MB", m.Alloc/1024/1024)
+			time.Sleep(1 * time.Second)
+		}
+	}()
+
+	log.Printf("Tentando alocar %d registros na memória...", *records)
+
+	// Loop para encher a memória
+	for i := 0; i < *records; i++ {
+		// Ctrl+C cancela o contexto em vez de matar o processo
+		if i%1_000_000 == 0 && ctx.Err() != nil {
+			log.Printf("Teste interrompido com %d registros alocados.", len(memoryHog))
+			return nil
+		}
+		memoryHog = append(memoryHog, models.LargeProduct{
+			ID:          i + 1,
+			Name:        fmt.Sprintf("Produto Super Pesado %d", i+1),
+			Description: "Esta é uma descrição longa para garantir que a string ocupe um espaço considerável na memória RAM do sistema.",
+		})
+	}
+
+	log.Printf("%d registros alocados. Se você está vendo esta mensagem, seu computador é um monstro! :)", len(memoryHog))
+	return nil
+}
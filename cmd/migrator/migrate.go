@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"migration-go/internal/migrate"
+)
+
+// runMigrate executa uma migração com a estratégia escolhida via --strategy
+func runMigrate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	strategyName := fs.String("strategy", migrate.StreamWorkers.Name(),
+		"estratégia de migração ("+strings.Join(migrate.Strategies(), ", ")+")")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	strategy, err := migrate.Lookup(*strategyName)
+	if err != nil {
+		return err
+	}
+
+	stats, err := migrate.Run(ctx, cfg, strategy)
+	if stats != nil {
+		fmt.Println(stats)
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"migration-go/internal/migrate"
+)
+
+// runRepair corrige no PostgreSQL as divergências de um relatório do verify
+func runRepair(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("repair", flag.ExitOnError)
+	input := fs.String("input", "", "relatório JSON gravado por 'verify --report' (obrigatório)")
+	output := fs.String("output", "", "grava em JSON o relatório do que foi alterado")
+	show := fs.Int("show", 20, "quantidade máxima de IDs exibidos por tipo de alteração")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if *input == "" {
+		return errors.New("informe o relatório do verify com --input")
+	}
+
+	verify, err := migrate.ReadVerifyReport(*input)
+	if err != nil {
+		return err
+	}
+
+	report, err := migrate.Repair(ctx, cfg, verify)
+	if report != nil {
+		fmt.Println(report)
+		printIDs("regravados a partir do MongoDB", report.Upserted, *show)
+		printIDs("removidos do PostgreSQL", report.Deleted, *show)
+		printIDs("não reparados", report.Failed, *show)
+		if *output != "" {
+			if err := report.WriteFile(*output); err != nil {
+				return err
+			}
+			fmt.Printf("Relatório gravado em %s\n", *output)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(report.Failed) > 0 {
+		return fmt.Errorf("%d IDs não puderam ser reparados", len(report.Failed))
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"migration-go/internal/migrate"
+)
+
+// runReplay regrava no PostgreSQL os documentos guardados na dead-letter
+func runReplay(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	stats, err := migrate.Replay(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Println(stats)
+	if stats.Failed > 0 {
+		return fmt.Errorf("%d entradas da dead-letter continuam falhando", stats.Failed)
+	}
+	return nil
+}
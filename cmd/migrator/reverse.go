@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"migration-go/internal/migrate"
+)
+
+// runReverse copia os produtos do PostgreSQL de volta para o MongoDB
+func runReverse(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("reverse", flag.ExitOnError)
+	drop := fs.Bool("drop-collection", false, "remove a coleção do MongoDB antes da cópia e grava com InsertMany em vez de upserts")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	stats, err := migrate.Reverse(ctx, cfg, migrate.ReverseOptions{DropCollection: *drop})
+	if stats != nil {
+		fmt.Println(stats)
+	}
+	if err != nil {
+		return err
+	}
+	if stats.Failed > 0 {
+		return fmt.Errorf("%d produtos não foram gravados no MongoDB", stats.Failed)
+	}
+	return nil
+}
@@ -2,51 +2,45 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"time"
 
-	"migration-go/internal/config"
 	"migration-go/internal/database"
 	"migration-go/internal/models"
 )
 
-const (
-	totalRecords = 15000
-	batchSize    = 5000
-)
-
-func main() {
-	ctx := context.Background()
+// runSeed popula a coleção de origem no MongoDB com produtos de teste
+func runSeed(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	total := fs.Int("total", 15000, "quantidade de produtos a inserir")
 
-	// ---- 1. CARREGAR CONFIGURAÇÃO ----
-	cfg, err := config.LoadConfig()
+	cfg, err := loadConfig(fs, args)
 	if err != nil {
-		log.Fatalf("Erro ao carregar configuração: %v", err)
+		return err
 	}
 
-	// ---- 2. CONEXÃO COM MONGODB ----
 	mongoManager := database.NewMongoManager(&cfg.MongoDB)
 	if err := mongoManager.Connect(ctx); err != nil {
-		log.Fatalf("Erro ao conectar ao MongoDB: %v", err)
+		return err
 	}
 	defer mongoManager.Disconnect(ctx)
 	fmt.Println("Conectado ao MongoDB!")
 
-	fmt.Println("Iniciando a inserção de 1 milhão de registros...")
+	fmt.Printf("Iniciando a inserção de %d registros...\n", *total)
 	startTime := time.Now()
 
-	// Chama a função para popular a collection
-	if err := setupMongoSource(ctx, mongoManager); err != nil {
-		log.Fatalf("Erro ao popular a origem no MongoDB: %v", err)
+	if err := setupMongoSource(ctx, mongoManager, *total, max(cfg.App.BatchSize, 1)); err != nil {
+		return fmt.Errorf("erro ao popular a origem no MongoDB: %w", err)
 	}
 
-	duration := time.Since(startTime)
-	fmt.Printf("Banco de dados MongoDB populado com sucesso em %s!\n", duration)
+	fmt.Printf("Banco de dados MongoDB populado com sucesso em %s!\n", time.Since(startTime))
+	return nil
 }
 
 // setupMongoSource popula a collection de origem no MongoDB usando lotes.
-func setupMongoSource(ctx context.Context, collection *database.MongoManager) error {
+func setupMongoSource(ctx context.Context, collection *database.MongoManager, totalRecords, batchSize int) error {
 	fmt.Println("Limpando a collection de origem no MongoDB...")
 
 	if err := collection.DropCollection(ctx); err != nil {
@@ -70,8 +64,7 @@ func setupMongoSource(ctx context.Context, collection *database.MongoManager) er
 		// Se o lote atingiu o tamanho máximo OU se este é o último registro,
 		// então insere o lote no banco de dados.
 		if len(docs) == batchSize || i == totalRecords-1 {
-			err := collection.InsertMany(ctx, docs)
-			if err != nil {
+			if err := collection.InsertMany(ctx, docs); err != nil {
 				return fmt.Errorf("erro ao inserir o lote de dados no Mongo: %w", err)
 			}
 
@@ -84,5 +77,7 @@ func setupMongoSource(ctx context.Context, collection *database.MongoManager) er
 	}
 
 	fmt.Printf("%d registros inseridos no total.\n", totalRecords)
-	return nil
+
+	// A migração lê ordenado por product_id para manter o checkpoint contíguo
+	return collection.CreateIndex(ctx, "product_id")
 }
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"migration-go/internal/migrate"
+)
+
+// runSync replica continuamente as alterações do MongoDB no PostgreSQL
+func runSync(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	preImages := fs.Bool("enable-pre-images", false, "altera a coleção de origem (collMod) para guardar pre-images, necessárias para aplicar deletes")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	stats, err := migrate.Sync(ctx, cfg, migrate.SyncOptions{EnablePreImages: *preImages})
+	if stats != nil {
+		fmt.Println(stats)
+	}
+	return err
+}
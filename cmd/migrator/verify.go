@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"migration-go/internal/migrate"
+)
+
+// runVerify compara origem e destino por contagem e por hash de conteúdo de
+// cada produto; qualquer divergência faz o comando terminar com erro
+func runVerify(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	method := fs.String("method", "rows", "rows compara todos os produtos; merkle compara digests por intervalo de product_id")
+	leafSize := fs.Int("leaf-size", 1000, "no método merkle, tamanho a partir do qual um intervalo divergente é comparado produto a produto")
+	reportFile := fs.String("report", "", "grava as divergências em JSON neste arquivo")
+	show := fs.Int("show", 20, "quantidade máxima de IDs exibidos por tipo de divergência")
+
+	cfg, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var report *migrate.VerifyReport
+	switch *method {
+	case "rows":
+		report, err = migrate.Verify(ctx, cfg)
+	case "merkle":
+		report, err = migrate.VerifyMerkle(ctx, cfg, *leafSize)
+	default:
+		err = fmt.Errorf("método de verificação desconhecido %q (disponíveis: rows, merkle)", *method)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(report)
+	printIDs("ausentes no PostgreSQL", report.Missing, *show)
+	printIDs("extras no PostgreSQL", report.Extra, *show)
+	printIDs("com conteúdo divergente", report.Mismatched, *show)
+
+	if *reportFile != "" {
+		if err := report.WriteFile(*reportFile); err != nil {
+			return err
+		}
+		fmt.Printf("Relatório gravado em %s\n", *reportFile)
+	}
+
+	if !report.OK() {
+		return fmt.Errorf("origem e destino divergem: %s", report)
+	}
+	fmt.Println("Origem e destino conferem!")
+	return nil
+}
+
+// printIDs exibe até limit IDs de uma lista de divergências
+func printIDs(label string, ids []int, limit int) {
+	if len(ids) == 0 {
+		return
+	}
+	fmt.Printf("%d IDs %s:", len(ids), label)
+	for i, id := range ids {
+		if i == limit {
+			fmt.Printf(" ... (+%d)", len(ids)-limit)
+			break
+		}
+		fmt.Printf(" %d", id)
+	}
+	fmt.Println()
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -33,8 +34,53 @@ type MongoConfig struct {
 }
 
 type AppConfig struct {
-	NumWorkers int
-	BatchSize  int
+	NumWorkers  int
+	BatchSize   int
+	WriteMethod string
+	Resume      bool
+
+	// OnConflict define o que fazer quando o id já existe no destino (error,
+	// skip, overwrite ou newer); Truncate esvazia o destino antes de migrar
+	OnConflict string
+	Truncate   bool
+
+	// Incremental migra apenas documentos criados/alterados desde a última execução
+	Incremental bool
+
+	// MaxRetries e RetryBackoff controlam a repetição de erros transitórios do PostgreSQL
+	MaxRetries   int
+	RetryBackoff time.Duration
+
+	// DeadLetter define para onde vão os documentos rejeitados: file, table ou none
+	DeadLetter     string
+	DeadLetterFile string
+
+	// Partitions e PartitionMethod dividem a leitura em intervalos de
+	// product_id, cada um com seu próprio cursor (estratégia partitioned)
+	Partitions      int
+	PartitionMethod string
+
+	// DryRun lê, decodifica e valida os documentos sem acessar o PostgreSQL
+	DryRun bool
+
+	// Mode escolhe o formato do destino: products (models.Product), jsonb
+	// (documento inteiro em TargetTable, com as colunas de Promote extraídas)
+	// ou mapping (colunas declaradas em MappingFile)
+	Mode        string
+	TargetTable string
+	Promote     string
+	MappingFile string
+
+	// Transforms é a cadeia de transformações aplicada a cada documento lido
+	// (ex.: "rename:title=name;drop:internal;filter:status=deleted")
+	Transforms string
+
+	// SourceFilter (Extended JSON), SourceProjection, SourceSort e SourceLimit
+	// restringem a consulta feita à coleção de origem
+	SourceFilter     string
+	SourceProjection string
+	SourceSort       string
+	SourceLimit      int
 }
 
 // LoadConfig carrega a configuração das variáveis de ambiente
@@ -63,8 +109,38 @@ func LoadConfig() (*Config, error) {
 			Collection: getEnv("MONGO_COLLECTION", "products"),
 		},
 		App: AppConfig{
-			NumWorkers: getEnvAsInt("NUM_WORKERS", 10),
-			BatchSize:  getEnvAsInt("BATCH_SIZE", 1000),
+			NumWorkers:  getEnvAsInt("NUM_WORKERS", 10),
+			BatchSize:   getEnvAsInt("BATCH_SIZE", 1000),
+			WriteMethod: getEnv("WRITE_METHOD", "insert"),
+			Resume:      getEnvAsBool("RESUME", false),
+
+			OnConflict: getEnv("ON_CONFLICT", "overwrite"),
+			Truncate:   getEnvAsBool("TRUNCATE", false),
+
+			Incremental: getEnvAsBool("INCREMENTAL", false),
+
+			MaxRetries:   getEnvAsInt("MAX_RETRIES", 5),
+			RetryBackoff: getEnvAsDuration("RETRY_BACKOFF", 200*time.Millisecond),
+
+			DeadLetter:     getEnv("DEAD_LETTER", "file"),
+			DeadLetterFile: getEnv("DEAD_LETTER_FILE", "dead_letter.ndjson"),
+
+			Partitions:      getEnvAsInt("PARTITIONS", 4),
+			PartitionMethod: getEnv("PARTITION_METHOD", "minmax"),
+
+			DryRun: getEnvAsBool("DRY_RUN", false),
+
+			Mode:        getEnv("MODE", "products"),
+			TargetTable: getEnv("TARGET_TABLE", ""),
+			Promote:     getEnv("PROMOTE", ""),
+			MappingFile: getEnv("MAPPING_FILE", ""),
+
+			Transforms: getEnv("TRANSFORMS", ""),
+
+			SourceFilter:     getEnv("SOURCE_FILTER", ""),
+			SourceProjection: getEnv("SOURCE_PROJECTION", ""),
+			SourceSort:       getEnv("SOURCE_SORT", ""),
+			SourceLimit:      getEnvAsInt("SOURCE_LIMIT", 0),
 		},
 	}
 
@@ -109,3 +185,21 @@ func getEnvAsInt(name string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvAsBool retorna o valor da variável de ambiente como booleano ou o valor padrão
+func getEnvAsBool(name string, defaultValue bool) bool {
+	valueStr := getEnv(name, "")
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsDuration retorna o valor da variável de ambiente como duração (ex.: "200ms") ou o valor padrão
+func getEnvAsDuration(name string, defaultValue time.Duration) time.Duration {
+	valueStr := getEnv(name, "")
+	if value, err := time.ParseDuration(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}
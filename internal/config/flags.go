@@ -0,0 +1,48 @@
+package config
+
+import "flag"
+
+// RegisterFlags registra flags que sobrescrevem os valores carregados do ambiente.
+// Os valores atuais da configuração são usados como padrão de cada flag.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	// PostgreSQL
+	fs.StringVar(&c.Postgres.Host, "pg-host", c.Postgres.Host, "host do PostgreSQL (POSTGRES_HOST)")
+	fs.StringVar(&c.Postgres.Port, "pg-port", c.Postgres.Port, "porta do PostgreSQL (POSTGRES_PORT)")
+	fs.StringVar(&c.Postgres.User, "pg-user", c.Postgres.User, "usuário do PostgreSQL (POSTGRES_USER)")
+	fs.StringVar(&c.Postgres.Password, "pg-password", c.Postgres.Password, "senha do PostgreSQL (POSTGRES_PASSWORD)")
+	fs.StringVar(&c.Postgres.Database, "pg-database", c.Postgres.Database, "banco do PostgreSQL (POSTGRES_DATABASE)")
+	fs.StringVar(&c.Postgres.SSLMode, "pg-sslmode", c.Postgres.SSLMode, "sslmode do PostgreSQL (POSTGRES_SSLMODE)")
+
+	// MongoDB
+	fs.StringVar(&c.MongoDB.Host, "mongo-host", c.MongoDB.Host, "host do MongoDB (MONGO_HOST)")
+	fs.StringVar(&c.MongoDB.Port, "mongo-port", c.MongoDB.Port, "porta do MongoDB (MONGO_PORT)")
+	fs.StringVar(&c.MongoDB.User, "mongo-user", c.MongoDB.User, "usuário do MongoDB (MONGO_USER)")
+	fs.StringVar(&c.MongoDB.Password, "mongo-password", c.MongoDB.Password, "senha do MongoDB (MONGO_PASSWORD)")
+	fs.StringVar(&c.MongoDB.Database, "mongo-database", c.MongoDB.Database, "banco do MongoDB (MONGO_DATABASE)")
+	fs.StringVar(&c.MongoDB.Collection, "collection", c.MongoDB.Collection, "coleção de origem no MongoDB (MONGO_COLLECTION)")
+
+	// Aplicação
+	fs.IntVar(&c.App.NumWorkers, "workers", c.App.NumWorkers, "número de workers de escrita (NUM_WORKERS)")
+	fs.IntVar(&c.App.BatchSize, "batch-size", c.App.BatchSize, "tamanho do lote de escrita (BATCH_SIZE)")
+	fs.StringVar(&c.App.WriteMethod, "write-method", c.App.WriteMethod, "método de escrita no PostgreSQL: insert ou copy (WRITE_METHOD)")
+	fs.BoolVar(&c.App.Resume, "resume", c.App.Resume, "retoma a partir do último checkpoint em vez de truncar o destino (RESUME)")
+	fs.StringVar(&c.App.OnConflict, "on-conflict", c.App.OnConflict, "política para ids já existentes: error, skip, overwrite ou newer (ON_CONFLICT)")
+	fs.BoolVar(&c.App.Truncate, "truncate", c.App.Truncate, "esvazia a tabela de destino antes de migrar (TRUNCATE)")
+	fs.BoolVar(&c.App.Incremental, "incremental", c.App.Incremental, "migra só documentos criados/alterados desde a última sincronização (INCREMENTAL)")
+	fs.IntVar(&c.App.MaxRetries, "max-retries", c.App.MaxRetries, "tentativas extras para erros transitórios do PostgreSQL (MAX_RETRIES)")
+	fs.DurationVar(&c.App.RetryBackoff, "retry-backoff", c.App.RetryBackoff, "espera base do backoff exponencial entre tentativas (RETRY_BACKOFF)")
+	fs.StringVar(&c.App.DeadLetter, "dead-letter", c.App.DeadLetter, "destino dos documentos rejeitados: file, table ou none (DEAD_LETTER)")
+	fs.StringVar(&c.App.DeadLetterFile, "dead-letter-file", c.App.DeadLetterFile, "arquivo NDJSON da dead-letter (DEAD_LETTER_FILE)")
+	fs.IntVar(&c.App.Partitions, "partitions", c.App.Partitions, "número de intervalos de product_id lidos em paralelo pela estratégia partitioned (PARTITIONS)")
+	fs.StringVar(&c.App.PartitionMethod, "partition-method", c.App.PartitionMethod, "como dividir os intervalos: minmax ou bucketauto (PARTITION_METHOD)")
+	fs.BoolVar(&c.App.DryRun, "dry-run", c.App.DryRun, "executa leitura e validação sem gravar, exibindo os comandos que seriam enviados ao PostgreSQL (DRY_RUN)")
+	fs.StringVar(&c.App.Mode, "mode", c.App.Mode, "formato do destino: products, jsonb ou mapping (MODE)")
+	fs.StringVar(&c.App.TargetTable, "target-table", c.App.TargetTable, "tabela de destino nos modos jsonb (padrão é o nome da coleção) e mapping (substitui a do arquivo) (TARGET_TABLE)")
+	fs.StringVar(&c.App.Promote, "promote", c.App.Promote, "colunas extraídas do documento no modo jsonb: coluna=caminho[:TIPO],... (PROMOTE)")
+	fs.StringVar(&c.App.MappingFile, "mapping", c.App.MappingFile, "arquivo JSON de mapeamento campo -> coluna do modo mapping (MAPPING_FILE)")
+	fs.StringVar(&c.App.Transforms, "transform", c.App.Transforms, "transformações aplicadas a cada documento, separadas por ';' (TRANSFORMS)")
+	fs.StringVar(&c.App.SourceFilter, "filter", c.App.SourceFilter, "filtro da consulta à origem em Extended JSON, ex.: '{\"tenant\":\"acme\"}' (SOURCE_FILTER)")
+	fs.StringVar(&c.App.SourceProjection, "projection", c.App.SourceProjection, "campos lidos da origem: campo1,campo2 ou -campo para excluir (SOURCE_PROJECTION)")
+	fs.StringVar(&c.App.SourceSort, "sort", c.App.SourceSort, "ordenação da leitura: campo1,-campo2 (SOURCE_SORT)")
+	fs.IntVar(&c.App.SourceLimit, "limit", c.App.SourceLimit, "máximo de documentos lidos da origem; 0 lê todos (SOURCE_LIMIT)")
+}
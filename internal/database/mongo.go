@@ -7,6 +7,7 @@ import (
 	"migration-go/internal/config"
 	"migration-go/internal/models"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -97,7 +98,41 @@ func (mm *MongoManager) InsertMany(ctx context.Context, products []interface{})
 	return nil
 }
 
+// UpsertMany grava os produtos com um único BulkWrite, atualizando com $set
+// os campos do modelo no documento de mesmo product_id ou criando-o se não
+// existir. Campos do documento que não fazem parte do modelo são mantidos.
+func (mm *MongoManager) UpsertMany(ctx context.Context, products []models.Product) (int64, error) {
+	if len(products) == 0 {
+		return 0, nil
+	}
+
+	writes := make([]mongo.WriteModel, len(products))
+	for i, p := range products {
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"product_id": p.ID}).
+			SetUpdate(bson.M{"$set": p}).
+			SetUpsert(true)
+	}
+
+	res, err := mm.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao gravar produtos no MongoDB: %w", err)
+	}
+	return res.UpsertedCount + res.MatchedCount, nil
+}
+
 // DropCollection remove todos os documentos da coleção
 func (mm *MongoManager) DropCollection(ctx context.Context) error {
 	return mm.collection.Drop(ctx)
 }
+
+// CreateIndex cria um índice ascendente no campo informado
+func (mm *MongoManager) CreateIndex(ctx context.Context, field string) error {
+	_, err := mm.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: field, Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao criar índice em %s: %w", field, err)
+	}
+	return nil
+}
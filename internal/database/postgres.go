@@ -61,8 +61,15 @@ func (pm *PostgresManager) Close() error {
 	return nil
 }
 
-// QueryProducts executa a query para buscar produtos
+// QueryProducts executa a query para buscar produtos, ordenados por id. As
+// colunas seguem a ordem de models.Product: id, name, description, price e created_at.
 func (pm *PostgresManager) QueryProducts(ctx context.Context) (*sql.Rows, error) {
-	query := "SELECT product_id, name, description, price, created_at FROM products ORDER BY product_id"
+	query := "SELECT id, name, description, price, created_at FROM products ORDER BY id"
 	return pm.db.QueryContext(ctx, query)
 }
+
+// QueryProductRange busca, ordenados por id, os produtos com id entre lo e hi (inclusive)
+func (pm *PostgresManager) QueryProductRange(ctx context.Context, lo, hi int) (*sql.Rows, error) {
+	query := "SELECT id, name, description, price, created_at FROM products WHERE id BETWEEN $1 AND $2 ORDER BY id"
+	return pm.db.QueryContext(ctx, query, lo, hi)
+}
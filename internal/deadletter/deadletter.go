@@ -0,0 +1,86 @@
+package deadletter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Etapas do pipeline em que um documento pode ser rejeitado
+const (
+	StageDecode    = "decode"
+	StageTransform = "transform"
+	StageInsert    = "insert"
+	StageChange    = "change"
+)
+
+// Tipos de destino aceitos em AppConfig.DeadLetter
+const (
+	KindNone  = "none"
+	KindFile  = "file"
+	KindTable = "table"
+)
+
+// Entry é um documento rejeitado, guardado como Extended JSON canônico para
+// que possa ser reprocessado sem perda de tipos
+type Entry struct {
+	ID       int64           `json:"-"`
+	Time     time.Time       `json:"time"`
+	Source   string          `json:"source"`
+	Stage    string          `json:"stage"`
+	Error    string          `json:"error"`
+	Document json.RawMessage `json:"document"`
+}
+
+// NewEntry monta uma entrada a partir do documento original (bson.Raw) ou de
+// qualquer valor serializável em BSON
+func NewEntry(source, stage string, doc interface{}, cause error) Entry {
+	entry := Entry{
+		Time:   time.Now().UTC(),
+		Source: source,
+		Stage:  stage,
+		Error:  cause.Error(),
+	}
+
+	ext, err := bson.MarshalExtJSON(doc, true, false)
+	if err != nil {
+		// Documento corrompido demais para virar JSON: guarda ao menos o motivo
+		ext, _ = json.Marshal(map[string]string{"$marshalError": err.Error()})
+	}
+	entry.Document = ext
+	return entry
+}
+
+// Decode converte o documento da entrada de volta para o tipo informado
+func (e Entry) Decode(v interface{}) error {
+	var raw bson.Raw
+	if err := bson.UnmarshalExtJSON(e.Document, true, &raw); err != nil {
+		return fmt.Errorf("erro ao ler documento da dead-letter: %w", err)
+	}
+	return bson.Unmarshal(raw, v)
+}
+
+// Sink recebe os documentos rejeitados durante a migração
+type Sink interface {
+	Write(ctx context.Context, entry Entry) error
+	Close() error
+}
+
+// Open cria o destino de dead-letter configurado. Retorna nil quando a
+// dead-letter está desativada.
+func Open(ctx context.Context, kind, path string, db *sql.DB) (Sink, error) {
+	switch kind {
+	case "", KindNone:
+		return nil, nil
+	case KindFile:
+		return OpenFile(path)
+	case KindTable:
+		return OpenTable(ctx, db)
+	default:
+		return nil, fmt.Errorf("dead-letter desconhecida %q (disponíveis: %s, %s, %s)", kind, KindFile, KindTable, KindNone)
+	}
+}
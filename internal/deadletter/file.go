@@ -0,0 +1,79 @@
+package deadletter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FileSink acrescenta as entradas em um arquivo NDJSON (uma entrada por linha)
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+	buf  *bufio.Writer
+	enc  *json.Encoder
+}
+
+// OpenFile abre o arquivo em modo append, criando-o se necessário
+func OpenFile(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo de dead-letter %s: %w", path, err)
+	}
+	buf := bufio.NewWriter(f)
+	return &FileSink{file: f, buf: buf, enc: json.NewEncoder(buf)}, nil
+}
+
+func (s *FileSink) Write(ctx context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enc.Encode(entry); err != nil {
+		return fmt.Errorf("erro ao gravar dead-letter: %w", err)
+	}
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.buf.Flush(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+// ScanFile lê o arquivo NDJSON e entrega cada entrada para fn
+func ScanFile(path string, fn func(Entry) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de dead-letter %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("linha %d de %s inválida: %w", line, path, err)
+		}
+		entry.ID = int64(line)
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
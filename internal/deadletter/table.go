@@ -0,0 +1,75 @@
+package deadletter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// TableSink grava as entradas na tabela migration_errors do PostgreSQL
+type TableSink struct {
+	db *sql.DB
+}
+
+// OpenTable cria a tabela migration_errors, se ainda não existir
+func OpenTable(ctx context.Context, db *sql.DB) (*TableSink, error) {
+	_, err := db.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS migration_errors (
+		id BIGSERIAL PRIMARY KEY,
+		source TEXT NOT NULL,
+		stage TEXT NOT NULL,
+		error TEXT NOT NULL,
+		document JSONB NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+	);`)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar a tabela migration_errors: %w", err)
+	}
+	return &TableSink{db: db}, nil
+}
+
+func (s *TableSink) Write(ctx context.Context, entry Entry) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO migration_errors (source, stage, error, document, created_at) VALUES ($1, $2, $3, $4, $5)`,
+		entry.Source, entry.Stage, entry.Error, string(entry.Document), entry.Time,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar dead-letter: %w", err)
+	}
+	return nil
+}
+
+func (s *TableSink) Close() error {
+	return nil
+}
+
+// Scan lê as entradas da origem informada e entrega cada uma para fn
+func (s *TableSink) Scan(ctx context.Context, source string, fn func(Entry) error) error {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, source, stage, error, document, created_at FROM migration_errors WHERE source = $1 ORDER BY id`,
+		source,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao ler migration_errors: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry Entry
+		var doc string
+		if err := rows.Scan(&entry.ID, &entry.Source, &entry.Stage, &entry.Error, &doc, &entry.Time); err != nil {
+			return err
+		}
+		entry.Document = []byte(doc)
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Delete remove uma entrada já reprocessada com sucesso
+func (s *TableSink) Delete(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM migration_errors WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,177 @@
+// Package job lê o arquivo que descreve uma migração de várias coleções, cada
+// uma com sua tabela de destino e as etapas das quais depende.
+package job
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Job é o conteúdo do arquivo de job
+type Job struct {
+	// Workers é o orçamento de workers de escrita dividido entre as etapas em
+	// execução; 0 usa NUM_WORKERS
+	Workers int    `json:"workers,omitempty"`
+	Steps   []Step `json:"steps"`
+}
+
+// Step migra uma coleção para uma tabela. Os campos vazios assumem o padrão
+// do modo (ex.: sem filtro, estratégia stream-goroutines); as demais opções,
+// como ON_CONFLICT e BATCH_SIZE, vêm da configuração do migrator.
+type Step struct {
+	// Name identifica a etapa em depends_on e no resumo; vazio, vale a coleção
+	Name       string `json:"name,omitempty"`
+	Collection string `json:"collection,omitempty"`
+	Table      string `json:"table,omitempty"`
+
+	// Mode, Strategy, Mapping e Promote equivalem a --mode, --strategy,
+	// --mapping e --promote do comando migrate
+	Mode     string `json:"mode,omitempty"`
+	Strategy string `json:"strategy,omitempty"`
+	Mapping  string `json:"mapping,omitempty"`
+	Promote  string `json:"promote,omitempty"`
+
+	// Transform, Filter (documento em Extended JSON), Projection, Sort e Limit
+	// equivalem a --transform, --filter, --projection, --sort e --limit
+	Transform  string          `json:"transform,omitempty"`
+	Filter     json.RawMessage `json:"filter,omitempty"`
+	Projection string          `json:"projection,omitempty"`
+	Sort       string          `json:"sort,omitempty"`
+	Limit      int             `json:"limit,omitempty"`
+
+	// Workers é quanto do orçamento a etapa reserva enquanto roda; 0 usa NUM_WORKERS
+	Workers int `json:"workers,omitempty"`
+
+	// DependsOn lista as etapas que precisam terminar com sucesso antes desta
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// Load lê e valida o arquivo de job em JSON
+func Load(path string) (*Job, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o job: %w", err)
+	}
+
+	var j Job
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&j); err != nil {
+		return nil, fmt.Errorf("job inválido em %s: %w", path, err)
+	}
+	if err := j.validate(); err != nil {
+		return nil, fmt.Errorf("job inválido em %s: %w", path, err)
+	}
+	return &j, nil
+}
+
+// validate confere nomes, limites e dependências das etapas
+func (j *Job) validate() error {
+	if len(j.Steps) == 0 {
+		return errors.New("steps precisa ter ao menos uma etapa")
+	}
+	if j.Workers < 0 {
+		return errors.New("workers não pode ser negativo")
+	}
+
+	names := map[string]bool{}
+	for i := range j.Steps {
+		s := &j.Steps[i]
+		if s.Name == "" {
+			s.Name = s.Collection
+		}
+		switch {
+		case s.Name == "":
+			return fmt.Errorf("etapa %d: name ou collection é obrigatório", i+1)
+		case names[s.Name]:
+			return fmt.Errorf("etapa %s declarada mais de uma vez", s.Name)
+		case s.Workers < 0 || s.Limit < 0:
+			return fmt.Errorf("etapa %s: workers e limit não podem ser negativos", s.Name)
+		case j.Workers > 0 && s.Workers > j.Workers:
+			return fmt.Errorf("etapa %s: workers (%d) maior que o orçamento do job (%d)", s.Name, s.Workers, j.Workers)
+		}
+		names[s.Name] = true
+	}
+
+	for _, s := range j.Steps {
+		for _, dep := range s.DependsOn {
+			switch {
+			case dep == s.Name:
+				return fmt.Errorf("etapa %s depende de si mesma", s.Name)
+			case !names[dep]:
+				return fmt.Errorf("etapa %s depende de %s, que não existe", s.Name, dep)
+			}
+		}
+	}
+
+	_, err := j.Levels()
+	return err
+}
+
+// Levels agrupa as etapas em níveis: cada nível depende apenas dos anteriores,
+// e as etapas de um mesmo nível são independentes entre si. Retorna erro se
+// houver dependência circular.
+func (j *Job) Levels() ([][]string, error) {
+	pending := map[string][]string{}
+	for _, s := range j.Steps {
+		pending[s.Name] = s.DependsOn
+	}
+
+	var levels [][]string
+	done := map[string]bool{}
+	for len(pending) > 0 {
+		var level []string
+		for name, deps := range pending {
+			if !slices.ContainsFunc(deps, func(d string) bool { return !done[d] }) {
+				level = append(level, name)
+			}
+		}
+		if len(level) == 0 {
+			return nil, fmt.Errorf("dependência circular entre as etapas %s", strings.Join(cycle(pending), ", "))
+		}
+
+		slices.Sort(level)
+		for _, name := range level {
+			done[name] = true
+			delete(pending, name)
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// cycle retorna, em ordem, as etapas pendentes envolvidas na dependência
+// circular. Etapas das quais nenhuma outra pendente depende são descartadas
+// até sobrar o ciclo, para que quem apenas espera por ele não seja citado.
+func cycle(pending map[string][]string) []string {
+	for {
+		required := map[string]bool{}
+		for _, deps := range pending {
+			for _, d := range deps {
+				if _, ok := pending[d]; ok {
+					required[d] = true
+				}
+			}
+		}
+		if len(required) == len(pending) {
+			break
+		}
+		for name := range pending {
+			if !required[name] {
+				delete(pending, name)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(pending))
+	for name := range pending {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
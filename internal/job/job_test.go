@@ -0,0 +1,98 @@
+package job
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// step cria uma etapa com o nome e as dependências informadas
+func step(name string, deps ...string) Step {
+	return Step{Name: name, DependsOn: deps}
+}
+
+func TestLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []Step
+		want    [][]string
+		wantErr string
+	}{
+		{
+			name:  "etapas independentes ficam no mesmo nível",
+			steps: []Step{step("c"), step("a"), step("b")},
+			want:  [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:  "cadeia",
+			steps: []Step{step("c", "b"), step("b", "a"), step("a")},
+			want:  [][]string{{"a"}, {"b"}, {"c"}},
+		},
+		{
+			name:  "diamante",
+			steps: []Step{step("orders", "customers", "products"), step("customers"), step("products"), step("items", "orders")},
+			want:  [][]string{{"customers", "products"}, {"orders"}, {"items"}},
+		},
+		{
+			name:    "ciclo entre duas etapas",
+			steps:   []Step{step("a", "b"), step("b", "a")},
+			wantErr: "dependência circular entre as etapas a, b",
+		},
+		{
+			name:    "ciclo não cita quem só depende dele",
+			steps:   []Step{step("x"), step("a", "c", "x"), step("b", "a"), step("c", "b"), step("d", "a")},
+			wantErr: "dependência circular entre as etapas a, b, c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := &Job{Steps: tt.steps}
+			got, err := j.Levels()
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("erro = %v, quer %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("níveis = %v, quer %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		job     Job
+		wantErr string
+	}{
+		{name: "válido", job: Job{Workers: 4, Steps: []Step{{Collection: "products", Workers: 4}, step("b", "products")}}},
+		{name: "sem etapas", job: Job{}, wantErr: "ao menos uma etapa"},
+		{name: "workers negativo", job: Job{Workers: -1, Steps: []Step{step("a")}}, wantErr: "workers não pode ser negativo"},
+		{name: "sem nome nem coleção", job: Job{Steps: []Step{{Table: "t"}}}, wantErr: "etapa 1: name ou collection"},
+		{name: "nome repetido", job: Job{Steps: []Step{{Collection: "a"}, step("a")}}, wantErr: "etapa a declarada mais de uma vez"},
+		{name: "limit negativo", job: Job{Steps: []Step{{Name: "a", Limit: -1}}}, wantErr: "não podem ser negativos"},
+		{name: "acima do orçamento", job: Job{Workers: 2, Steps: []Step{{Name: "a", Workers: 3}}}, wantErr: "maior que o orçamento"},
+		{name: "depende de si mesma", job: Job{Steps: []Step{step("a", "a")}}, wantErr: "etapa a depende de si mesma"},
+		{name: "dependência inexistente", job: Job{Steps: []Step{step("a", "b")}}, wantErr: "etapa a depende de b, que não existe"},
+		{name: "ciclo", job: Job{Steps: []Step{step("a", "b"), step("b", "a")}}, wantErr: "dependência circular"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.job.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("erro inesperado: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("erro = %v, quer conter %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// natural converte um valor BSON no tipo Go correspondente. Tipos sem
+// equivalente direto (documentos, arrays, binários...) viram Extended JSON.
+func natural(rv bson.RawValue) interface{} {
+	switch rv.Type {
+	case bson.TypeString, bson.TypeSymbol:
+		return rv.StringValue()
+	case bson.TypeInt32:
+		return int64(rv.Int32())
+	case bson.TypeInt64:
+		return rv.Int64()
+	case bson.TypeDouble:
+		return rv.Double()
+	case bson.TypeDecimal128:
+		return rv.Decimal128().String()
+	case bson.TypeBoolean:
+		return rv.Boolean()
+	case bson.TypeDateTime:
+		return rv.Time()
+	case bson.TypeTimestamp:
+		t, _ := rv.Timestamp()
+		return time.Unix(int64(t), 0)
+	case bson.TypeObjectID:
+		return rv.ObjectID()
+	}
+
+	text, err := ExtJSON(rv)
+	if err != nil {
+		return rv.String()
+	}
+	return text
+}
+
+// convert aplica Field.Convert a um valor já no tipo Go natural (ou vindo do
+// default em JSON)
+func (f *Field) convert(v interface{}) (interface{}, error) {
+	switch f.Convert {
+	case ConvertString:
+		return toString(v), nil
+	case ConvertInt:
+		return toInt(v)
+	case ConvertFloat:
+		return toFloat(v)
+	case ConvertBool:
+		return toBool(v)
+	case ConvertTimestamp:
+		return toTime(v)
+	case ConvertJSON:
+		// Só os defaults chegam aqui (campos do documento viram Extended JSON
+		// direto do BSON); objetos, arrays e strings precisam voltar a ser JSON
+		if v == nil {
+			return nil, nil
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+
+	// Sem conversão explícita: ObjectID vira texto e números do JSON (float64)
+	// inteiros ficam como inteiros
+	switch v := v.(type) {
+	case primitive.ObjectID:
+		return v.Hex(), nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v), nil
+		}
+	}
+	return v, nil
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case primitive.ObjectID:
+		return v.Hex()
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func toInt(v interface{}) (int64, error) {
+	switch v := v.(type) {
+	case int64:
+		return v, nil
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("%v não é inteiro", v)
+		}
+		return int64(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q não é inteiro", v)
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("não é possível converter %T para inteiro", v)
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q não é numérico", v)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("não é possível converter %T para número", v)
+}
+
+func toBool(v interface{}) (bool, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case int64:
+		return v != 0, nil
+	case float64:
+		return v != 0, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return false, fmt.Errorf("%q não é booleano", v)
+		}
+		return b, nil
+	}
+	return false, fmt.Errorf("não é possível converter %T para booleano", v)
+}
+
+func toTime(v interface{}) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case int64:
+		return time.UnixMilli(v), nil
+	case float64:
+		return time.UnixMilli(int64(v)), nil
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(v))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%q não é uma data RFC 3339", v)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("não é possível converter %T para data", v)
+}
+
+// ExtJSON converte um valor BSON em Extended JSON relaxado
+func ExtJSON(rv bson.RawValue) (string, error) {
+	doc, err := bson.Marshal(bson.D{{Key: "v", Value: rv}})
+	if err != nil {
+		return "", err
+	}
+	ext, err := bson.MarshalExtJSON(bson.Raw(doc), false, false)
+	if err != nil {
+		return "", err
+	}
+	// Remove o envelope {"v": ...}
+	text := strings.TrimSpace(string(ext))
+	text = strings.TrimPrefix(text, `{"v":`)
+	return strings.TrimSuffix(text, "}"), nil
+}
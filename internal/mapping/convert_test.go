@@ -0,0 +1,35 @@
+package mapping
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConvertJSONDefault(t *testing.T) {
+	tests := []struct {
+		def  string
+		want interface{}
+	}{
+		{`{"a": 1, "b": [true, null]}`, `{"a":1,"b":[true,null]}`},
+		{`[1, "x"]`, `[1,"x"]`},
+		{`"texto"`, `"texto"`},
+		{`2.5`, `2.5`},
+		{`false`, `false`},
+		{`null`, nil},
+	}
+	for _, tt := range tests {
+		f := Field{Convert: ConvertJSON}
+		var v interface{}
+		if err := json.Unmarshal([]byte(tt.def), &v); err != nil {
+			t.Fatal(err)
+		}
+		got, err := f.convert(v)
+		if err != nil {
+			t.Errorf("convert(%s): erro inesperado: %v", tt.def, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("convert(%s) = %#v, quer %#v", tt.def, got, tt.want)
+		}
+	}
+}
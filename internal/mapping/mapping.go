@@ -0,0 +1,231 @@
+// Package mapping lê o arquivo declarativo que descreve como os campos de uma
+// coleção do MongoDB viram colunas de uma tabela do PostgreSQL.
+package mapping
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// Mapping é o conteúdo do arquivo de mapeamento
+type Mapping struct {
+	// Collection é a coleção de origem; vazia, vale a coleção configurada
+	Collection string  `json:"collection"`
+	Table      string  `json:"table"`
+	Fields     []Field `json:"fields"`
+}
+
+// Field mapeia um campo da origem (caminho com pontos) para uma coluna
+type Field struct {
+	Source string `json:"source"`
+	Column string `json:"column"`
+	Type   string `json:"type"`
+
+	// Default é usado quando o campo está ausente ou nulo no documento
+	Default json.RawMessage `json:"default,omitempty"`
+
+	// Convert força a conversão do valor antes do INSERT; veja Conversions
+	Convert string `json:"convert,omitempty"`
+
+	// Key marca a chave primária (exatamente um campo); Required gera NOT NULL
+	// e rejeita documentos sem o campo e sem default
+	Key      bool `json:"key,omitempty"`
+	Required bool `json:"required,omitempty"`
+
+	path         []string
+	defaultValue interface{}
+}
+
+// Conversões aceitas em Field.Convert
+const (
+	ConvertAuto      = ""          // tipo natural do valor BSON
+	ConvertString    = "string"    // texto; ObjectID em hexadecimal
+	ConvertInt       = "int"       // inteiro de 64 bits
+	ConvertFloat     = "float"     // ponto flutuante
+	ConvertBool      = "bool"      // booleano
+	ConvertTimestamp = "timestamp" // data: BSON date, RFC 3339 ou epoch em milissegundos
+	ConvertJSON      = "json"      // Extended JSON relaxado, para colunas JSON/JSONB
+)
+
+// Conversions lista as conversões aceitas, para mensagens de erro e ajuda
+var Conversions = []string{ConvertString, ConvertInt, ConvertFloat, ConvertBool, ConvertTimestamp, ConvertJSON}
+
+// pgTypePattern restringe os tipos aceitos, que entram no DDL sem escape
+var pgTypePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9 _]*(\([0-9, ]+\))?(\[\])?$`)
+
+// ValidType indica se t tem a forma de um tipo do PostgreSQL (ex.: TEXT,
+// NUMERIC(10, 2), INT[]) e pode ser usado em um DDL
+func ValidType(t string) bool {
+	return pgTypePattern.MatchString(t)
+}
+
+// Load lê e valida o arquivo de mapeamento em JSON
+func Load(path string) (*Mapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o mapeamento: %w", err)
+	}
+
+	var m Mapping
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("mapeamento inválido em %s: %w", path, err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("mapeamento inválido em %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// validate confere o mapeamento e prepara caminhos e valores padrão
+func (m *Mapping) validate() error {
+	if m.Table == "" {
+		return errors.New("table é obrigatório")
+	}
+	if len(m.Fields) == 0 {
+		return errors.New("fields precisa ter ao menos um campo")
+	}
+
+	keys := 0
+	columns := map[string]bool{}
+	for i := range m.Fields {
+		f := &m.Fields[i]
+		switch {
+		case f.Source == "" || f.Column == "":
+			return fmt.Errorf("campo %d: source e column são obrigatórios", i+1)
+		case columns[f.Column]:
+			return fmt.Errorf("coluna %s declarada mais de uma vez", f.Column)
+		case !ValidType(f.Type):
+			return fmt.Errorf("coluna %s: tipo inválido %q", f.Column, f.Type)
+		case f.Convert != ConvertAuto && !slices.Contains(Conversions, f.Convert):
+			return fmt.Errorf("coluna %s: conversão desconhecida %q (disponíveis: %s)",
+				f.Column, f.Convert, strings.Join(Conversions, ", "))
+		}
+		columns[f.Column] = true
+		if f.Key {
+			keys++
+		}
+
+		f.path = strings.Split(f.Source, ".")
+		if len(f.Default) > 0 {
+			if err := json.Unmarshal(f.Default, &f.defaultValue); err != nil {
+				return fmt.Errorf("coluna %s: default inválido: %w", f.Column, err)
+			}
+			v, err := f.convert(f.defaultValue)
+			if err != nil {
+				return fmt.Errorf("coluna %s: default incompatível com a conversão: %w", f.Column, err)
+			}
+			f.defaultValue = v
+		}
+	}
+	if keys != 1 {
+		return fmt.Errorf("exatamente um campo deve ter key=true (encontrados %d)", keys)
+	}
+	return nil
+}
+
+// Key retorna a coluna da chave primária
+func (m *Mapping) Key() string {
+	for _, f := range m.Fields {
+		if f.Key {
+			return f.Column
+		}
+	}
+	return ""
+}
+
+// Columns retorna as colunas de destino na ordem dos valores de Row
+func (m *Mapping) Columns() []string {
+	cols := make([]string, len(m.Fields))
+	for i, f := range m.Fields {
+		cols[i] = f.Column
+	}
+	return cols
+}
+
+// DDL monta o CREATE TABLE da tabela de destino
+func (m *Mapping) DDL() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CREATE TABLE IF NOT EXISTS %s (\n", pq.QuoteIdentifier(m.Table))
+	for i, f := range m.Fields {
+		fmt.Fprintf(&sb, "\t%s %s", pq.QuoteIdentifier(f.Column), f.Type)
+		switch {
+		case f.Key:
+			sb.WriteString(" PRIMARY KEY")
+		case f.Required:
+			sb.WriteString(" NOT NULL")
+		}
+		if f.defaultValue != nil {
+			sb.WriteString(" DEFAULT " + literal(f.defaultValue))
+		}
+		if i < len(m.Fields)-1 {
+			sb.WriteByte(',')
+		}
+		sb.WriteByte('\n')
+	}
+	sb.WriteString(");")
+	return sb.String()
+}
+
+// Row converte um documento nos valores das colunas, na ordem de Columns
+func (m *Mapping) Row(doc bson.Raw) ([]interface{}, error) {
+	values := make([]interface{}, len(m.Fields))
+	for i := range m.Fields {
+		f := &m.Fields[i]
+		v, err := f.value(doc)
+		if err != nil {
+			return nil, fmt.Errorf("campo %s: %w", f.Source, err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// value extrai e converte o valor do campo, aplicando o default se necessário
+func (f *Field) value(doc bson.Raw) (interface{}, error) {
+	rv, err := doc.LookupErr(f.path...)
+	missing := errors.Is(err, bsoncore.ErrElementNotFound) ||
+		(err == nil && (rv.Type == bson.TypeNull || rv.Type == bson.TypeUndefined))
+	if err != nil && !missing {
+		return nil, err
+	}
+
+	if missing {
+		if f.defaultValue != nil {
+			return f.defaultValue, nil
+		}
+		if f.Required || f.Key {
+			return nil, errors.New("campo obrigatório ausente")
+		}
+		return nil, nil
+	}
+
+	if f.Convert == ConvertJSON {
+		return ExtJSON(rv)
+	}
+	return f.convert(natural(rv))
+}
+
+// literal formata um valor padrão como literal SQL
+func literal(v interface{}) string {
+	switch v := v.(type) {
+	case bool, int64, float64:
+		return fmt.Sprint(v)
+	case time.Time:
+		return pq.QuoteLiteral(v.Format(time.RFC3339Nano))
+	default:
+		return pq.QuoteLiteral(fmt.Sprint(v))
+	}
+}
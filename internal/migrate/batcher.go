@@ -0,0 +1,46 @@
+package migrate
+
+// Batch é um lote de linhas gravado em uma única transação. Seq é a ordem do
+// lote dentro da sua partição de leitura.
+type Batch[T any] struct {
+	Partition int
+	Seq       int64
+	Rows      []T
+}
+
+// batcher acumula linhas até completar um lote e então o entrega para flushFn
+type batcher[T any] struct {
+	size      int
+	partition int
+	seq       int64
+	batch     []T
+	flushFn   func(Batch[T])
+}
+
+func newBatcher[T any](size, partition int, flushFn func(Batch[T])) *batcher[T] {
+	return &batcher[T]{
+		size:      size,
+		partition: partition,
+		batch:     make([]T, 0, size),
+		flushFn:   flushFn,
+	}
+}
+
+// add inclui uma linha no lote atual, descarregando-o quando fica cheio
+func (b *batcher[T]) add(row T) error {
+	b.batch = append(b.batch, row)
+	if len(b.batch) >= b.size {
+		b.flush()
+	}
+	return nil
+}
+
+// flush entrega o lote atual, se houver, e inicia um novo
+func (b *batcher[T]) flush() {
+	if len(b.batch) == 0 {
+		return
+	}
+	b.seq++
+	b.flushFn(Batch[T]{Partition: b.partition, Seq: b.seq, Rows: b.batch})
+	b.batch = make([]T, 0, b.size)
+}
@@ -0,0 +1,456 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"migration-go/internal/config"
+	"migration-go/internal/database"
+	"migration-go/internal/deadletter"
+	"migration-go/internal/models"
+	"migration-go/internal/transform"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SyncStats resume a replicação contínua via change streams
+type SyncStats struct {
+	Events       int64
+	Upserts      int64
+	Deletes      int64
+	Skipped      int64
+	DeadLettered int64
+	Duration     time.Duration
+}
+
+// String formata o resumo da replicação para exibição no terminal
+func (s SyncStats) String() string {
+	return fmt.Sprintf("eventos=%d upserts=%d deletes=%d ignorados=%d dead-letter=%d duração=%s",
+		s.Events, s.Upserts, s.Deletes, s.Skipped, s.DeadLettered, s.Duration)
+}
+
+// SyncOptions são as opções da replicação que alteram a origem e, por isso,
+// só valem quando pedidas explicitamente na linha de comando
+type SyncOptions struct {
+	// EnablePreImages habilita as pre-images da coleção de origem (collMod),
+	// necessárias para aplicar deletes
+	EnablePreImages bool
+}
+
+// changeEvent são os campos do evento de change stream usados na replicação
+type changeEvent struct {
+	OperationType            string   `bson:"operationType"`
+	FullDocument             bson.Raw `bson:"fullDocument"`
+	FullDocumentBeforeChange bson.Raw `bson:"fullDocumentBeforeChange"`
+	DocumentKey              bson.Raw `bson:"documentKey"`
+}
+
+// Sync aplica continuamente no PostgreSQL os eventos de insert, update, replace
+// e delete da coleção de origem. O resume token é salvo na mesma transação de
+// cada lote aplicado, de modo que um reinício continua exatamente de onde parou.
+// Exige que o MongoDB rode como replica set.
+func Sync(ctx context.Context, cfg *config.Config, sopts SyncOptions) (*SyncStats, error) {
+	// Os eventos não passam pelo filtro da origem: um documento que deixasse de
+	// atendê-lo continuaria no destino
+	if err := wholeCollection(&cfg.App, "sync"); err != nil {
+		return nil, err
+	}
+
+	pgManager, mongoManager, disconnect, err := connect(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer disconnect()
+
+	r, err := newReplicator(ctx, cfg, pgManager.GetDB())
+	if err != nil {
+		return nil, err
+	}
+	defer r.close()
+
+	preparePreImages(ctx, mongoManager, sopts.EnablePreImages)
+	opts := r.streamOptions()
+	token, err := r.tokens.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token != nil {
+		fmt.Printf("Retomando a replicação de %s a partir do resume token salvo.\n", r.source)
+		opts.SetStartAfter(token)
+	} else {
+		fmt.Printf("Nenhum resume token para %s; replicando a partir de agora.\n", r.source)
+	}
+
+	return r.run(ctx, mongoManager.GetCollection(), opts)
+}
+
+// replicator aplica eventos de change stream na tabela products
+type replicator struct {
+	db         *sql.DB
+	source     string
+	tokens     *resumeTokenStore
+	deadLetter deadletter.Sink
+	retry      retryPolicy
+	transform  transform.Chain
+	batchSize  int
+	stats      SyncStats
+}
+
+// newReplicator prepara o destino, a tabela de resume tokens e a dead-letter
+func newReplicator(ctx context.Context, cfg *config.Config, db *sql.DB) (*replicator, error) {
+	if err := createProductsTable(ctx, db); err != nil {
+		return nil, fmt.Errorf("erro ao configurar o destino no PostgreSQL: %w", err)
+	}
+
+	source := sourceKey(cfg)
+	tokens := &resumeTokenStore{db: db, key: source}
+	if err := tokens.setup(ctx); err != nil {
+		return nil, fmt.Errorf("erro ao criar a tabela de resume tokens: %w", err)
+	}
+
+	chain, err := transform.Parse(cfg.App.Transforms)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetter, err := deadletter.Open(ctx, cfg.App.DeadLetter, cfg.App.DeadLetterFile, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &replicator{
+		db:         db,
+		source:     source,
+		tokens:     tokens,
+		deadLetter: deadLetter,
+		retry:      newRetryPolicy(cfg),
+		transform:  chain,
+		batchSize:  max(cfg.App.BatchSize, 1),
+	}, nil
+}
+
+func (r *replicator) close() {
+	if r.deadLetter != nil {
+		r.deadLetter.Close()
+	}
+}
+
+// streamOptions pede o documento completo após updates e, quando disponível,
+// a imagem anterior, necessária para saber o product_id de um delete
+func (r *replicator) streamOptions() *options.ChangeStreamOptions {
+	return options.ChangeStream().
+		SetFullDocument(options.UpdateLookup).
+		SetFullDocumentBeforeChange(options.WhenAvailable)
+}
+
+// run abre o change stream e aplica os eventos em lotes até o contexto ser
+// cancelado ou o stream ser invalidado
+func (r *replicator) run(ctx context.Context, collection *mongo.Collection, opts *options.ChangeStreamOptions) (*SyncStats, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete", "invalidate"}}}}},
+	}
+	stream, err := collection.Watch(ctx, pipeline, opts)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir o change stream (o MongoDB precisa ser um replica set): %w", err)
+	}
+	defer stream.Close(context.WithoutCancel(ctx))
+
+	fmt.Println("Replicação iniciada. Pressione Ctrl+C para encerrar.")
+	startTime := time.Now()
+	applyCtx := context.WithoutCancel(ctx)
+
+	var runErr error
+	for runErr == nil && stream.Next(ctx) {
+		// Agrupa os eventos já disponíveis no buffer do cursor em um único lote
+		events := []bson.Raw{cloneRaw(stream.Current)}
+		for len(events) < r.batchSize && stream.RemainingBatchLength() > 0 && stream.Next(ctx) {
+			events = append(events, cloneRaw(stream.Current))
+		}
+		runErr = r.applyBatch(applyCtx, events, stream.ResumeToken())
+	}
+
+	r.stats.Duration = time.Since(startTime)
+	if runErr == nil {
+		runErr = stream.Err()
+	}
+	if errors.Is(runErr, context.Canceled) {
+		fmt.Println("Replicação encerrada; o resume token do último lote aplicado está salvo.")
+		runErr = nil
+	}
+	return &r.stats, runErr
+}
+
+// applyBatch aplica os eventos e salva o resume token na mesma transação. Se o
+// PostgreSQL rejeitar um evento de forma permanente, o lote é reaplicado com
+// cada evento isolado em um SAVEPOINT: os rejeitados vão para a dead-letter e
+// a replicação segue. Só erros transitórios (esgotadas as tentativas)
+// interrompem a replicação, sem avançar o resume token.
+func (r *replicator) applyBatch(ctx context.Context, events []bson.Raw, token bson.Raw) error {
+	stats, skipped, err := r.applyEvents(ctx, events, token, false)
+	if err != nil && !isTransient(err) && !errors.Is(err, errInvalidated) {
+		log.Printf("Lote de %d eventos rejeitado (%v); reaplicando evento a evento", len(events), err)
+		stats, skipped, err = r.applyEvents(ctx, events, token, true)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao aplicar lote de %d eventos: %w", len(events), err)
+	}
+
+	// Dead-letter só depois do commit para não duplicar entradas nas tentativas
+	stats.Skipped = int64(len(skipped))
+	for _, ev := range skipped {
+		if r.deadLetter == nil {
+			break
+		}
+		if err := r.deadLetter.Write(ctx, deadletter.NewEntry(r.source, deadletter.StageChange, ev.raw, ev.reason)); err != nil {
+			log.Printf("Erro ao gravar evento na dead-letter: %v", err)
+			continue
+		}
+		stats.DeadLettered++
+	}
+
+	r.stats.Events += int64(len(events))
+	r.stats.Upserts += stats.Upserts
+	r.stats.Deletes += stats.Deletes
+	r.stats.Skipped += stats.Skipped
+	r.stats.DeadLettered += stats.DeadLettered
+
+	before := r.stats.Events - int64(len(events))
+	if r.stats.Events/progressEvery != before/progressEvery {
+		fmt.Printf("... %d eventos aplicados ...\n", r.stats.Events)
+	}
+	return nil
+}
+
+// applyEvents aplica os eventos em uma transação, com retentativas. Com
+// isolate, cada evento roda em um SAVEPOINT e um erro permanente dele vira
+// motivo de rejeição em vez de reverter o lote.
+func (r *replicator) applyEvents(ctx context.Context, events []bson.Raw, token bson.Raw, isolate bool) (SyncStats, []skippedEvent, error) {
+	var stats SyncStats
+	var skipped []skippedEvent
+	_, _, err := r.retry.do(ctx, func() (int64, error) {
+		stats, skipped = SyncStats{}, nil
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		defer tx.Rollback()
+
+		for _, raw := range events {
+			var reason error
+			if isolate {
+				reason, err = r.applyIsolated(ctx, tx, raw, &stats)
+			} else {
+				reason, err = r.applyEvent(ctx, tx, raw, &stats)
+			}
+			if err != nil {
+				return 0, err
+			}
+			if reason != nil {
+				skipped = append(skipped, skippedEvent{raw: raw, reason: reason})
+			}
+		}
+		if err := r.tokens.save(ctx, tx, token); err != nil {
+			return 0, err
+		}
+		return 0, tx.Commit()
+	})
+	return stats, skipped, err
+}
+
+// applyIsolated aplica um evento dentro de um SAVEPOINT. Um erro permanente
+// desfaz apenas o evento e é devolvido como motivo de rejeição.
+func (r *replicator) applyIsolated(ctx context.Context, tx *sql.Tx, raw bson.Raw, stats *SyncStats) (reason error, err error) {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT change_event"); err != nil {
+		return nil, err
+	}
+
+	eventStats := *stats
+	reason, err = r.applyEvent(ctx, tx, raw, &eventStats)
+	if err != nil && !isTransient(err) && !errors.Is(err, errInvalidated) {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT change_event"); rbErr != nil {
+			return nil, rbErr
+		}
+		return fmt.Errorf("rejeitado pelo PostgreSQL: %w", err), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	*stats = eventStats
+	_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT change_event")
+	return reason, err
+}
+
+// skippedEvent é um evento que não pôde ser aplicado e vai para a dead-letter
+type skippedEvent struct {
+	raw    bson.Raw
+	reason error
+}
+
+// errInvalidated indica que a coleção foi removida ou renomeada
+var errInvalidated = errors.New("change stream invalidado (a coleção foi removida ou renomeada)")
+
+// applyEvent aplica um evento dentro da transação do lote. Eventos que não
+// podem ser aplicados retornam o motivo em reason, sem abortar o lote.
+func (r *replicator) applyEvent(ctx context.Context, tx *sql.Tx, raw bson.Raw, stats *SyncStats) (reason error, err error) {
+	var ev changeEvent
+	if err := bson.Unmarshal(raw, &ev); err != nil {
+		return fmt.Errorf("evento inválido: %w", err), nil
+	}
+
+	switch ev.OperationType {
+	case "insert", "update", "replace":
+		// Em updates o documento pode já ter sido removido quando o lookup rodou;
+		// o delete correspondente chega em seguida
+		if len(ev.FullDocument) == 0 {
+			return nil, nil
+		}
+		records, err := r.transform.ApplyRaw(ev.FullDocument)
+		if err != nil {
+			return fmt.Errorf("erro ao transformar fullDocument: %w", err), nil
+		}
+		if len(records) == 0 {
+			return nil, nil
+		}
+		products := make([]models.Product, len(records))
+		for i, rec := range records {
+			if err := bson.Unmarshal(rec, &products[i]); err != nil {
+				return fmt.Errorf("erro ao decodificar fullDocument: %w", err), nil
+			}
+			// Antecipa as rejeições permanentes mais comuns sem precisar
+			// reaplicar o lote evento a evento
+			if err := validateProduct(products[i]); err != nil {
+				return err, nil
+			}
+		}
+		if _, err := insertProducts(ctx, tx, products, ConflictOverwrite); err != nil {
+			return nil, err
+		}
+		stats.Upserts++
+
+	case "delete":
+		id, ok := deletedProductID(ev)
+		if !ok {
+			return errors.New("delete sem pre-image: não é possível descobrir o product_id"), nil
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM products WHERE id = $1`, id); err != nil {
+			return nil, err
+		}
+		stats.Deletes++
+
+	case "invalidate":
+		return nil, errInvalidated
+	}
+	return nil, nil
+}
+
+// deletedProductID obtém o product_id do documento removido a partir da
+// pre-image (ou da documentKey, quando product_id faz parte da shard key)
+func deletedProductID(ev changeEvent) (int, bool) {
+	for _, doc := range []bson.Raw{ev.FullDocumentBeforeChange, ev.DocumentKey} {
+		if len(doc) == 0 {
+			continue
+		}
+		var key struct {
+			ID *int `bson:"product_id"`
+		}
+		if err := bson.Unmarshal(doc, &key); err == nil && key.ID != nil {
+			return *key.ID, true
+		}
+	}
+	return 0, false
+}
+
+// preparePreImages garante que deletes tragam o documento removido. Com
+// enable, liga as pre-images da coleção (MongoDB 6.0+); sem ele, a coleção não
+// é alterada e, se as pre-images estiverem desligadas, apenas avisa que os
+// deletes não serão aplicados. Falhas são apenas avisadas.
+func preparePreImages(ctx context.Context, mm *database.MongoManager, enable bool) {
+	if !enable {
+		enabled, err := preImagesEnabled(ctx, mm)
+		if err != nil {
+			log.Printf("Aviso: não foi possível consultar as pre-images da coleção: %v", err)
+		} else if !enabled {
+			log.Printf("Aviso: pre-images desligadas na coleção %s; deletes NÃO serão aplicados e irão para a dead-letter (use --enable-pre-images)", mm.GetCollection().Name())
+		}
+		return
+	}
+
+	cmd := bson.D{
+		{Key: "collMod", Value: mm.GetCollection().Name()},
+		{Key: "changeStreamPreAndPostImages", Value: bson.M{"enabled": true}},
+	}
+	if err := mm.GetDatabase().RunCommand(ctx, cmd).Err(); err != nil {
+		log.Printf("Aviso: não foi possível habilitar pre-images na coleção; deletes irão para a dead-letter: %v", err)
+		return
+	}
+	fmt.Printf("Pre-images habilitadas na coleção %s.\n", mm.GetCollection().Name())
+}
+
+// preImagesEnabled consulta se a coleção já guarda pre-images
+func preImagesEnabled(ctx context.Context, mm *database.MongoManager) (bool, error) {
+	specs, err := mm.GetDatabase().ListCollectionSpecifications(ctx, bson.M{"name": mm.GetCollection().Name()})
+	if err != nil {
+		return false, err
+	}
+	if len(specs) == 0 || specs[0].Options == nil {
+		return false, nil
+	}
+	enabled, ok := specs[0].Options.Lookup("changeStreamPreAndPostImages", "enabled").BooleanOK()
+	return ok && enabled, nil
+}
+
+// cloneRaw copia o documento atual do cursor, cujo buffer é reaproveitado
+func cloneRaw(raw bson.Raw) bson.Raw {
+	return append(bson.Raw(nil), raw...)
+}
+
+// resumeTokenStore persiste o resume token do change stream por coleção
+type resumeTokenStore struct {
+	db  *sql.DB
+	key string
+}
+
+// setup cria a tabela de resume tokens, se ainda não existir
+func (s *resumeTokenStore) setup(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS migration_resume_tokens (
+		source TEXT PRIMARY KEY,
+		token BYTEA NOT NULL,
+		updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+	);`)
+	return err
+}
+
+// load retorna o resume token salvo, ou nil se não houver
+func (s *resumeTokenStore) load(ctx context.Context) (bson.Raw, error) {
+	var token []byte
+	err := s.db.QueryRowContext(ctx,
+		`SELECT token FROM migration_resume_tokens WHERE source = $1`, s.key,
+	).Scan(&token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler resume token de %s: %w", s.key, err)
+	}
+	return bson.Raw(token), nil
+}
+
+// save grava o resume token dentro da transação informada
+func (s *resumeTokenStore) save(ctx context.Context, db execer, token bson.Raw) error {
+	_, err := db.ExecContext(ctx, `
+	INSERT INTO migration_resume_tokens (source, token, updated_at)
+	VALUES ($1, $2, now())
+	ON CONFLICT (source) DO UPDATE
+	SET token = EXCLUDED.token, updated_at = EXCLUDED.updated_at`,
+		s.key, []byte(token),
+	)
+	return err
+}
@@ -0,0 +1,149 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// checkpointStore persiste no PostgreSQL o maior product_id confirmado de forma
+// contígua para cada coleção de origem
+type checkpointStore struct {
+	db  *sql.DB
+	key string
+}
+
+// setup cria a tabela de estado, se ainda não existir
+func (s *checkpointStore) setup(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS migration_checkpoints (
+		source TEXT PRIMARY KEY,
+		last_product_id BIGINT NOT NULL,
+		updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+	);`)
+	return err
+}
+
+// load retorna o checkpoint salvo e se ele existe
+func (s *checkpointStore) load(ctx context.Context) (int, bool, error) {
+	var id int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT last_product_id FROM migration_checkpoints WHERE source = $1`, s.key,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("erro ao ler checkpoint de %s: %w", s.key, err)
+	}
+	return id, true, nil
+}
+
+// save grava o novo checkpoint
+func (s *checkpointStore) save(ctx context.Context, id int) error {
+	_, err := s.db.ExecContext(ctx, `
+	INSERT INTO migration_checkpoints (source, last_product_id, updated_at)
+	VALUES ($1, $2, now())
+	ON CONFLICT (source) DO UPDATE
+	SET last_product_id = EXCLUDED.last_product_id, updated_at = EXCLUDED.updated_at`,
+		s.key, id,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao salvar checkpoint de %s: %w", s.key, err)
+	}
+	return nil
+}
+
+// reset remove o checkpoint, usado quando a migração recomeça do zero
+func (s *checkpointStore) reset(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM migration_checkpoints WHERE source = $1`, s.key)
+	return err
+}
+
+// checkpointer acompanha os lotes gravados e mantém o checkpoint de product_id
+type checkpointer interface {
+	// complete registra o resultado de um lote
+	complete(ctx context.Context, o BatchOutcome) error
+	// finish informa que a leitura de uma partição terminou após emitir batches lotes
+	finish(ctx context.Context, partition int, batches int64) error
+	// current retorna o checkpoint atual
+	current() int
+}
+
+// watermark acompanha os lotes confirmados e avança o checkpoint apenas
+// quando todos os lotes anteriores também foram confirmados. Como a leitura
+// é ordenada por product_id, isso garante que todo ID até o checkpoint já
+// está no destino, mesmo com workers terminando fora de ordem.
+type watermark struct {
+	mu      sync.Mutex
+	store   *checkpointStore
+	next    int64
+	pending map[int64]BatchOutcome
+	value   int
+	stalled bool
+}
+
+func newWatermark(store *checkpointStore, start int) *watermark {
+	return &watermark{
+		store:   store,
+		next:    1,
+		pending: make(map[int64]BatchOutcome),
+		value:   start,
+	}
+}
+
+// complete registra o resultado de um lote e persiste o checkpoint se ele avançar
+func (w *watermark) complete(ctx context.Context, o BatchOutcome) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stalled {
+		return nil
+	}
+	w.pending[o.Seq] = o
+
+	advanced := false
+	for {
+		next, ok := w.pending[w.next]
+		if !ok {
+			break
+		}
+		if !next.Settled() {
+			// Um lote com produtos perdidos trava o checkpoint: a retomada precisa relê-lo
+			w.stalled = true
+			break
+		}
+		delete(w.pending, w.next)
+		w.value = max(w.value, next.MaxID)
+		w.next++
+		advanced = true
+	}
+
+	// Sem store (modo incremental) o checkpoint é só acompanhado em memória
+	if !advanced || w.store == nil {
+		return nil
+	}
+	return w.store.save(ctx, w.value)
+}
+
+// finish não tem efeito: com uma única sequência de lotes, o checkpoint já
+// avança a cada lote confirmado
+func (w *watermark) finish(ctx context.Context, partition int, batches int64) error {
+	return nil
+}
+
+// current retorna o checkpoint atual
+func (w *watermark) current() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.value
+}
+
+// settled indica se os lotes 1..batches foram todos gravados ou rejeitados
+func (w *watermark) settled(batches int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !w.stalled && w.next > batches
+}
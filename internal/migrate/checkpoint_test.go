@@ -0,0 +1,247 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// committed é um lote gravado com sucesso cobrindo os IDs lo..hi
+func committed(partition int, seq int64, lo, hi int) BatchOutcome {
+	return BatchOutcome{Partition: partition, Seq: seq, Size: hi - lo + 1, MinID: lo, MaxID: hi, Committed: true}
+}
+
+// rolledBack é um lote revertido do qual salvaged produtos foram regravados ou
+// enviados para a dead-letter
+func rolledBack(partition int, seq int64, lo, hi, salvaged int) BatchOutcome {
+	return BatchOutcome{
+		Partition: partition, Seq: seq, Size: hi - lo + 1, MinID: lo, MaxID: hi,
+		Err: errors.New("falhou"), Salvaged: salvaged,
+	}
+}
+
+// checkpointStep é um evento aplicado ao checkpointer seguido do valor esperado
+type checkpointStep struct {
+	outcome *BatchOutcome
+	finish  *finishCall
+	want    int
+}
+
+type finishCall struct {
+	partition int
+	batches   int64
+}
+
+func completeStep(o BatchOutcome, want int) checkpointStep {
+	return checkpointStep{outcome: &o, want: want}
+}
+
+func finishStep(partition int, batches int64, want int) checkpointStep {
+	return checkpointStep{finish: &finishCall{partition, batches}, want: want}
+}
+
+func runCheckpointSteps(t *testing.T, c checkpointer, steps []checkpointStep) {
+	t.Helper()
+	ctx := context.Background()
+	for i, s := range steps {
+		var err error
+		if s.outcome != nil {
+			err = c.complete(ctx, *s.outcome)
+		} else {
+			err = c.finish(ctx, s.finish.partition, s.finish.batches)
+		}
+		if err != nil {
+			t.Fatalf("passo %d: erro inesperado: %v", i+1, err)
+		}
+		if got := c.current(); got != s.want {
+			t.Fatalf("passo %d: checkpoint = %d, quer %d", i+1, got, s.want)
+		}
+	}
+}
+
+func TestWatermark(t *testing.T) {
+	tests := []struct {
+		name  string
+		start int
+		steps []checkpointStep
+	}{
+		{
+			name: "lotes em ordem",
+			steps: []checkpointStep{
+				completeStep(committed(0, 1, 1, 100), 100),
+				completeStep(committed(0, 2, 101, 200), 200),
+				completeStep(committed(0, 3, 201, 250), 250),
+			},
+		},
+		{
+			name: "lotes fora de ordem só avançam quando o anterior chega",
+			steps: []checkpointStep{
+				completeStep(committed(0, 2, 101, 200), 0),
+				completeStep(committed(0, 3, 201, 300), 0),
+				completeStep(committed(0, 1, 1, 100), 300),
+			},
+		},
+		{
+			name: "lote não gravado trava o checkpoint",
+			steps: []checkpointStep{
+				completeStep(committed(0, 1, 1, 100), 100),
+				completeStep(rolledBack(0, 2, 101, 200, 40), 100),
+				completeStep(committed(0, 3, 201, 300), 100),
+				completeStep(committed(0, 4, 301, 400), 100),
+			},
+		},
+		{
+			name: "lote travado chegando depois dos seguintes",
+			steps: []checkpointStep{
+				completeStep(committed(0, 3, 201, 300), 0),
+				completeStep(committed(0, 1, 1, 100), 100),
+				completeStep(rolledBack(0, 2, 101, 200, 0), 100),
+			},
+		},
+		{
+			name: "lote revertido mas todo regravado ou na dead-letter avança",
+			steps: []checkpointStep{
+				completeStep(rolledBack(0, 1, 1, 100, 100), 100),
+				completeStep(committed(0, 2, 101, 200), 200),
+			},
+		},
+		{
+			name:  "retomada parte do checkpoint salvo",
+			start: 500,
+			steps: []checkpointStep{
+				completeStep(committed(0, 2, 601, 700), 500),
+				completeStep(committed(0, 1, 501, 600), 700),
+			},
+		},
+		{
+			name:  "finish não altera o checkpoint",
+			start: 10,
+			steps: []checkpointStep{
+				completeStep(committed(0, 1, 11, 20), 20),
+				finishStep(0, 1, 20),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runCheckpointSteps(t, newWatermark(nil, tt.start), tt.steps)
+		})
+	}
+}
+
+func TestWatermarkSettled(t *testing.T) {
+	ctx := context.Background()
+	w := newWatermark(nil, 0)
+	if !w.settled(0) {
+		t.Error("sem lotes, settled(0) deveria ser verdadeiro")
+	}
+
+	w.complete(ctx, committed(0, 2, 11, 20))
+	if w.settled(2) {
+		t.Error("settled(2) com o lote 1 pendente")
+	}
+	w.complete(ctx, committed(0, 1, 1, 10))
+	if !w.settled(2) {
+		t.Error("settled(2) falso com os lotes 1 e 2 gravados")
+	}
+	if w.settled(3) {
+		t.Error("settled(3) verdadeiro sem o lote 3")
+	}
+
+	w.complete(ctx, rolledBack(0, 3, 21, 30, 5))
+	if w.settled(3) {
+		t.Error("settled(3) verdadeiro com o lote 3 travado")
+	}
+}
+
+func TestPartitionedWatermark(t *testing.T) {
+	parts := []Partition{
+		{Index: 0, Min: 1, Max: 1000},
+		{Index: 1, Min: 1001, Max: 2000},
+		{Index: 2, Min: 2001, Max: 3000},
+	}
+
+	tests := []struct {
+		name  string
+		start int
+		steps []checkpointStep
+	}{
+		{
+			name: "avança dentro da primeira partição",
+			steps: []checkpointStep{
+				completeStep(committed(0, 1, 1, 100), 100),
+				completeStep(committed(0, 2, 101, 200), 200),
+			},
+		},
+		{
+			name: "partições seguintes esperam a anterior terminar",
+			steps: []checkpointStep{
+				completeStep(committed(1, 1, 1001, 1500), 0),
+				completeStep(committed(1, 2, 1501, 2000), 0),
+				finishStep(1, 2, 0),
+				completeStep(committed(0, 1, 1, 900), 900),
+				finishStep(0, 1, 2000),
+			},
+		},
+		{
+			name: "partição concluída conta até o seu Max mesmo sem documentos no fim",
+			steps: []checkpointStep{
+				completeStep(committed(0, 1, 1, 700), 700),
+				finishStep(0, 1, 1000),
+				completeStep(committed(1, 1, 1001, 1100), 1100),
+			},
+		},
+		{
+			name: "partição vazia não bloqueia as seguintes",
+			steps: []checkpointStep{
+				completeStep(committed(0, 1, 1, 1000), 1000),
+				finishStep(0, 1, 1000),
+				finishStep(1, 0, 2000),
+				completeStep(committed(2, 1, 2001, 2500), 2500),
+				finishStep(2, 1, 3000),
+			},
+		},
+		{
+			name: "finish antes do último lote confirmado espera o lote",
+			steps: []checkpointStep{
+				completeStep(committed(0, 1, 1, 500), 500),
+				finishStep(0, 2, 500),
+				completeStep(committed(0, 2, 501, 999), 1000),
+			},
+		},
+		{
+			name: "lote travado na primeira partição bloqueia as seguintes",
+			steps: []checkpointStep{
+				completeStep(committed(0, 1, 1, 500), 500),
+				completeStep(rolledBack(0, 2, 501, 1000, 10), 500),
+				finishStep(0, 2, 500),
+				completeStep(committed(1, 1, 1001, 2000), 500),
+				finishStep(1, 1, 500),
+			},
+		},
+		{
+			name: "lote travado em partição posterior não desfaz as anteriores",
+			steps: []checkpointStep{
+				completeStep(committed(0, 1, 1, 1000), 1000),
+				finishStep(0, 1, 1000),
+				completeStep(rolledBack(1, 1, 1001, 1500, 0), 1000),
+				completeStep(committed(1, 2, 1501, 2000), 1000),
+				finishStep(1, 2, 1000),
+			},
+		},
+		{
+			name:  "retomada no meio da primeira partição",
+			start: 600,
+			steps: []checkpointStep{
+				completeStep(committed(0, 2, 801, 1000), 600),
+				completeStep(committed(0, 1, 601, 800), 1000),
+				finishStep(0, 2, 1000),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runCheckpointSteps(t, newPartitionedWatermark(nil, tt.start, parts), tt.steps)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Políticas de conflito aceitas em AppConfig.OnConflict, aplicadas quando o
+// id já existe na tabela de destino
+const (
+	ConflictError     = "error"     // INSERT simples: o conflito é um erro permanente
+	ConflictSkip      = "skip"      // mantém a linha existente
+	ConflictOverwrite = "overwrite" // substitui a linha existente
+	ConflictNewer     = "newer"     // mantém a linha com created_at mais recente
+)
+
+// validateConflictPolicy verifica se a política informada é conhecida
+func validateConflictPolicy(policy string) error {
+	switch policy {
+	case ConflictError, ConflictSkip, ConflictOverwrite, ConflictNewer:
+		return nil
+	default:
+		return fmt.Errorf("política de conflito desconhecida %q (disponíveis: %s, %s, %s, %s)",
+			policy, ConflictError, ConflictSkip, ConflictOverwrite, ConflictNewer)
+	}
+}
+
+// conflictClause retorna o ON CONFLICT da tabela products correspondente à
+// política, ou "" para ConflictError
+func conflictClause(policy string) string {
+	if policy == ConflictNewer {
+		return " ON CONFLICT (id) DO UPDATE SET " + updateAssignments("id", productColumns) +
+			" WHERE products.created_at IS NULL OR EXCLUDED.created_at > products.created_at"
+	}
+	return upsertClause("id", productColumns, policy)
+}
+
+// upsertClause retorna o ON CONFLICT (key) de ConflictSkip ou ConflictOverwrite
+// para uma tabela com as colunas informadas, ou "" para as demais políticas.
+// key e columns devem vir já entre aspas quando necessário.
+func upsertClause(key string, columns []string, policy string) string {
+	switch policy {
+	case ConflictSkip:
+		return " ON CONFLICT (" + key + ") DO NOTHING"
+	case ConflictOverwrite:
+		return " ON CONFLICT (" + key + ") DO UPDATE SET " + updateAssignments(key, columns)
+	default:
+		return ""
+	}
+}
+
+// updateAssignments monta "col = EXCLUDED.col" para todas as colunas exceto a chave
+func updateAssignments(key string, columns []string) string {
+	sets := make([]string, 0, len(columns)-1)
+	for _, col := range columns {
+		if col != key {
+			sets = append(sets, col+" = EXCLUDED."+col)
+		}
+	}
+	return strings.Join(sets, ", ")
+}
@@ -0,0 +1,128 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"migration-go/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// copySink grava via protocolo COPY (COPY products FROM STDIN). Como o COPY
+// não aceita ON CONFLICT, nas políticas de upsert o lote é copiado para uma
+// tabela temporária e então mesclado em products com INSERT ... SELECT.
+type copySink struct {
+	db     *sql.DB
+	policy string
+}
+
+// NewWriter reserva uma conexão exclusiva para o worker, que passa a ter o seu
+// próprio stream de COPY
+func (s copySink) NewWriter(ctx context.Context) (Writer, error) {
+	w := &copyWriter{db: s.db, policy: s.policy}
+	if err := w.connect(ctx); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// copyWriter envia cada lote em um COPY dentro de uma transação, exigência do
+// lib/pq para o protocolo
+type copyWriter struct {
+	db     *sql.DB
+	policy string
+	conn   *sql.Conn
+}
+
+// connect reserva uma nova conexão caso a anterior tenha sido descartada
+func (w *copyWriter) connect(ctx context.Context) error {
+	if w.conn != nil {
+		return nil
+	}
+	conn, err := w.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao reservar conexão para COPY: %w", err)
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *copyWriter) Write(ctx context.Context, batch []models.Product) (int64, error) {
+	if err := w.connect(ctx); err != nil {
+		return 0, err
+	}
+
+	rows, err := w.copy(ctx, batch)
+	if err != nil && isConnError(err) {
+		// A conexão reservada caiu: descarta para que a próxima tentativa use outra
+		w.conn.Close()
+		w.conn = nil
+	}
+	return rows, err
+}
+
+func (w *copyWriter) copy(ctx context.Context, batch []models.Product) (int64, error) {
+	tx, err := w.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	table := "products"
+	if w.policy != ConflictError {
+		table = "products_stage"
+		_, err := tx.ExecContext(ctx,
+			`CREATE TEMP TABLE products_stage (LIKE products INCLUDING DEFAULTS) ON COMMIT DROP`)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table, productColumns...))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, p := range batch {
+		if _, err := stmt.ExecContext(ctx, p.ID, p.Name, p.Description, p.Price, p.CreatedAt); err != nil {
+			stmt.Close()
+			return 0, err
+		}
+	}
+
+	// Exec sem argumentos descarrega o buffer e finaliza o COPY
+	res, err := stmt.ExecContext(ctx)
+	if err != nil {
+		stmt.Close()
+		return 0, err
+	}
+	if err := stmt.Close(); err != nil {
+		return 0, err
+	}
+
+	if w.policy != ConflictError {
+		cols := strings.Join(productColumns, ", ")
+		res, err = tx.ExecContext(ctx,
+			"INSERT INTO products ("+cols+") SELECT "+cols+" FROM products_stage"+conflictClause(w.policy))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	rows, _ := res.RowsAffected()
+	return rows, nil
+}
+
+func (w *copyWriter) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
@@ -0,0 +1,107 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"migration-go/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Cutover faz a cópia inicial consistente com um único instante do cluster e,
+// em seguida, replica as alterações a partir desse mesmo instante. Assim,
+// escritas que chegam ao MongoDB durante a cópia não se perdem: ou estão no
+// snapshot, ou são reaplicadas pelo change stream (de forma idempotente).
+//
+// A leitura usa read concern snapshot, limitado pela janela de histórico do
+// servidor (minSnapshotHistoryWindowInSeconds, 5 minutos por padrão); para
+// coleções grandes, aumente esse parâmetro e garanta oplog suficiente.
+func Cutover(ctx context.Context, cfg *config.Config, strategy Strategy, sopts SyncOptions) (*Stats, *SyncStats, error) {
+	if cfg.App.Resume || cfg.App.Incremental || cfg.App.DryRun {
+		return nil, nil, errors.New("o cutover faz uma cópia completa e não combina com --resume, --incremental ou --dry-run")
+	}
+	// Todas as leituras usam a mesma sessão snapshot, que o driver não permite
+	// usar em paralelo
+	if cr, ok := strategy.(concurrentReader); ok && cr.concurrentReads() {
+		return nil, nil, fmt.Errorf("a estratégia %s lê com vários cursores em paralelo e não pode compartilhar a sessão snapshot do cutover", strategy.Name())
+	}
+	// O change stream replica a coleção inteira; uma cópia parcial ficaria
+	// inconsistente com as alterações reaplicadas depois
+	if err := wholeCollection(&cfg.App, "cutover"); err != nil {
+		return nil, nil, err
+	}
+
+	if !cfg.App.Truncate {
+		fmt.Println("Aviso: sem --truncate, linhas do destino que não existem na origem são mantidas e o resultado não será um snapshot exato.")
+	}
+
+	pgManager, mongoManager, disconnect, err := connect(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer disconnect()
+
+	// As pre-images precisam estar ligadas antes do snapshot para que deletes
+	// feitos durante a cópia possam ser reaplicados
+	preparePreImages(ctx, mongoManager, sopts.EnablePreImages)
+
+	// ---- 1. FIXA O INSTANTE DO SNAPSHOT ----
+	sess, err := mongoManager.GetClient().StartSession(options.Session().SetSnapshot(true))
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao iniciar sessão snapshot: %w", err)
+	}
+	defer sess.EndSession(context.Background())
+	snapCtx := mongo.NewSessionContext(ctx, sess)
+
+	clusterTime, err := pinSnapshot(snapCtx, mongoManager.GetCollection(), sess)
+	if err != nil {
+		return nil, nil, err
+	}
+	fmt.Printf("Snapshot fixado no cluster time %d.%d\n", clusterTime.T, clusterTime.I)
+
+	// ---- 2. CÓPIA INICIAL NO SNAPSHOT ----
+	stats, err := runEngine(snapCtx, cfg, strategy, pgManager, mongoManager)
+	if err != nil {
+		return stats, nil, err
+	}
+	if unsettled := stats.Unsettled(); len(unsettled) > 0 {
+		return stats, nil, fmt.Errorf("%d lotes da cópia inicial não foram gravados; corrija e refaça o cutover", len(unsettled))
+	}
+
+	// ---- 3. REPLICAÇÃO A PARTIR DO SNAPSHOT ----
+	r, err := newReplicator(ctx, cfg, pgManager.GetDB())
+	if err != nil {
+		return stats, nil, err
+	}
+	defer r.close()
+
+	fmt.Println("Cópia inicial concluída; reaplicando as alterações desde o snapshot...")
+	opts := r.streamOptions().SetStartAtOperationTime(clusterTime)
+	syncStats, err := r.run(ctx, mongoManager.GetCollection(), opts)
+	return stats, syncStats, err
+}
+
+// pinSnapshot executa a primeira leitura da sessão, que fixa o atClusterTime
+// usado por todas as leituras seguintes, e retorna esse instante
+func pinSnapshot(ctx context.Context, collection *mongo.Collection, sess mongo.Session) (*primitive.Timestamp, error) {
+	err := collection.FindOne(ctx, bson.M{}).Err()
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("erro ao fixar o snapshot (o MongoDB precisa ser um replica set 5.0+): %w", err)
+	}
+
+	// O driver só expõe o atClusterTime escolhido pelo servidor via XSession
+	if xs, ok := sess.(mongo.XSession); ok {
+		if ts := xs.ClientSession().SnapshotTime; ts != nil {
+			return ts, nil
+		}
+	}
+	if ts := sess.OperationTime(); ts != nil {
+		return ts, nil
+	}
+	return nil, errors.New("o servidor não informou o instante do snapshot")
+}
@@ -0,0 +1,205 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"migration-go/internal/config"
+	"migration-go/internal/deadletter"
+
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Modos de migração aceitos em AppConfig.Mode
+const (
+	ModeProducts = "products" // decodifica em models.Product e grava em products
+	ModeJSONB    = "jsonb"    // grava cada documento inteiro em uma coluna JSONB
+)
+
+// tableTarget descreve uma tabela de destino genérica, preenchida a partir de
+// documentos arbitrários da origem. row converte um documento nos valores de
+// columns, na mesma ordem; key é a coluna usada no ON CONFLICT.
+type tableTarget struct {
+	mode    string
+	table   string
+	key     string
+	columns []string
+	ddl     string
+	row     func(doc bson.Raw) ([]interface{}, error)
+}
+
+// quoted retorna a tabela, as colunas e a chave entre aspas
+func (t *tableTarget) quoted() (table string, columns []string, key string) {
+	columns = make([]string, len(t.columns))
+	for i, c := range t.columns {
+		columns[i] = pq.QuoteIdentifier(c)
+	}
+	return pq.QuoteIdentifier(t.table), columns, pq.QuoteIdentifier(t.key)
+}
+
+// statement retorna o montador do INSERT de n linhas na tabela, com a
+// cláusula da política de conflito
+func (t *tableTarget) statement(policy string) func(rows int) string {
+	table, columns, key := t.quoted()
+	conflict := upsertClause(key, columns, policy)
+	return func(rows int) string {
+		return insertStatement(table, columns, rows) + conflict
+	}
+}
+
+// docRow é um documento já convertido, mantido junto do original para a dead-letter
+type docRow struct {
+	doc    bson.Raw
+	values []interface{}
+}
+
+// tableWriter grava as linhas de um tableTarget com INSERTs de múltiplas
+// linhas, um lote por transação
+type tableWriter struct {
+	db        *sql.DB
+	columns   int
+	statement func(rows int) string
+}
+
+func newTableWriter(db *sql.DB, target *tableTarget, policy string) tableWriter {
+	return tableWriter{db: db, columns: len(target.columns), statement: target.statement(policy)}
+}
+
+func (w tableWriter) Write(ctx context.Context, batch []docRow) (int64, error) {
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := insertRows(ctx, tx, batch, w.columns, w.statement, func(r docRow) []interface{} {
+		return r.values
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return rows, nil
+}
+
+func (w tableWriter) Close() error {
+	return nil
+}
+
+// checkDocumentMode recusa as opções que os modos genéricos não suportam. Sem
+// product_id não há partições, checkpoint, retomada ou marca d'água, e a
+// escrita é sempre por INSERT.
+func checkDocumentMode(app *config.AppConfig, mode string, strategy Strategy) error {
+	if k, ok := strategy.(productKeyed); ok && k.keyedByProductID() {
+		return fmt.Errorf("o modo %s lê a origem em ordem de _id e não suporta a estratégia %s", mode, strategy.Name())
+	}
+	switch {
+	case app.Resume || app.Incremental:
+		return fmt.Errorf("o modo %s não tem checkpoint por product_id e não suporta --resume ou --incremental", mode)
+	case app.OnConflict == ConflictNewer:
+		return fmt.Errorf("o modo %s não suporta --on-conflict=%s", mode, ConflictNewer)
+	case app.WriteMethod != "" && app.WriteMethod != WriteInsert:
+		return fmt.Errorf("o modo %s grava com INSERT e não suporta --write-method=%s", mode, app.WriteMethod)
+	}
+	return validateConflictPolicy(app.OnConflict)
+}
+
+// documentEngine cria o Engine de um destino genérico: a leitura segue _id,
+// cada registro vira uma linha por target.row e os lotes vão para newWriter
+func documentEngine(cfg *config.Config, collection *mongo.Collection, target *tableTarget, newWriter func(ctx context.Context) (rowWriter[docRow], error)) (*Engine, error) {
+	e, err := engineFor(cfg, collection, "_id", nil)
+	if err != nil {
+		return nil, err
+	}
+	// A origem inclui a tabela para que o replay, que só conhece products,
+	// não confunda estas entradas com as do modo padrão
+	e.source = sourceKey(cfg) + " -> " + target.table
+	e.flow = &pipeline[docRow]{
+		Engine: e,
+		key:    "_id",
+		convert: func(rec bson.Raw) (docRow, error) {
+			values, err := target.row(rec)
+			return docRow{doc: rec, values: values}, err
+		},
+		document:  func(r docRow) interface{} { return r.doc },
+		newWriter: newWriter,
+	}
+	return e, nil
+}
+
+// runDocuments conecta aos bancos, cria a tabela do destino genérico e migra
+// a coleção para ela com a estratégia informada
+func runDocuments(ctx context.Context, cfg *config.Config, strategy Strategy, target *tableTarget) (*Stats, error) {
+	if err := checkDocumentMode(&cfg.App, target.mode, strategy); err != nil {
+		return nil, err
+	}
+	if cfg.App.DryRun {
+		return dryRunDocuments(ctx, cfg, strategy, target)
+	}
+
+	pgManager, mongoManager, disconnect, err := connect(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer disconnect()
+
+	db := pgManager.GetDB()
+	writer := newTableWriter(db, target, cfg.App.OnConflict)
+	engine, err := documentEngine(cfg, mongoManager.GetCollection(), target, func(ctx context.Context) (rowWriter[docRow], error) {
+		return writer, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Preparando a tabela de destino '%s' no PostgreSQL...\n", target.table)
+	if _, err := db.ExecContext(ctx, target.ddl); err != nil {
+		return nil, fmt.Errorf("erro ao criar a tabela %s: %w", target.table, err)
+	}
+	if cfg.App.Truncate {
+		fmt.Printf("Truncando a tabela de destino '%s'...\n", target.table)
+		if _, err := db.ExecContext(ctx, "TRUNCATE TABLE "+pq.QuoteIdentifier(target.table)); err != nil {
+			return nil, err
+		}
+	}
+
+	deadLetter, err := deadletter.Open(ctx, cfg.App.DeadLetter, cfg.App.DeadLetterFile, db)
+	if err != nil {
+		return nil, err
+	}
+	if deadLetter != nil {
+		defer deadLetter.Close()
+		engine.deadLetter = deadLetter
+	}
+
+	fmt.Printf("Iniciando a migração MongoDB -> PostgreSQL (modo %s, tabela %s, estratégia %s, conflitos %s)...\n",
+		target.mode, target.table, strategy.Name(), cfg.App.OnConflict)
+	startTime := time.Now()
+	err = strategy.Migrate(ctx, engine)
+
+	stats := engine.stats()
+	stats.Strategy = strategy.Name()
+	stats.Duration = time.Since(startTime)
+	if rolledBack := stats.RolledBack(); len(rolledBack) > 0 {
+		fmt.Printf("%d lotes revertidos; os lotes abaixo NÃO foram gravados:\n", len(rolledBack))
+		for _, o := range rolledBack {
+			fmt.Printf("  %s\n", o)
+		}
+	}
+	if errors.Is(err, context.Canceled) {
+		fmt.Printf("Migração interrompida após %s: %d registros gravados.\n", stats.Duration, stats.Written)
+	}
+	if err != nil {
+		return stats, fmt.Errorf("migração %s interrompida: %w", target.mode, err)
+	}
+
+	fmt.Printf("Migração concluída em %s!\n", stats.Duration)
+	return stats, nil
+}
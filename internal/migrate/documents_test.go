@@ -0,0 +1,70 @@
+package migrate
+
+import (
+	"testing"
+
+	"migration-go/internal/config"
+)
+
+func TestTableTargetStatement(t *testing.T) {
+	target := &tableTarget{table: "orders raw", key: "source_id", columns: []string{"source_id", "doc", "migrated_at"}}
+	tests := []struct {
+		policy string
+		rows   int
+		want   string
+	}{
+		{ConflictError, 1, `INSERT INTO "orders raw" ("source_id", "doc", "migrated_at") VALUES ($1, $2, $3)`},
+		{ConflictSkip, 2, `INSERT INTO "orders raw" ("source_id", "doc", "migrated_at") VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT ("source_id") DO NOTHING`},
+		{ConflictOverwrite, 1, `INSERT INTO "orders raw" ("source_id", "doc", "migrated_at") VALUES ($1, $2, $3) ON CONFLICT ("source_id") DO UPDATE SET "doc" = EXCLUDED."doc", "migrated_at" = EXCLUDED."migrated_at"`},
+	}
+	for _, tt := range tests {
+		if got := target.statement(tt.policy)(tt.rows); got != tt.want {
+			t.Errorf("statement(%s)(%d) =\n  %s\nquer\n  %s", tt.policy, tt.rows, got, tt.want)
+		}
+	}
+}
+
+func TestConflictClause(t *testing.T) {
+	const sets = "name = EXCLUDED.name, description = EXCLUDED.description, price = EXCLUDED.price, created_at = EXCLUDED.created_at"
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{ConflictError, ""},
+		{ConflictSkip, " ON CONFLICT (id) DO NOTHING"},
+		{ConflictOverwrite, " ON CONFLICT (id) DO UPDATE SET " + sets},
+		{ConflictNewer, " ON CONFLICT (id) DO UPDATE SET " + sets +
+			" WHERE products.created_at IS NULL OR EXCLUDED.created_at > products.created_at"},
+	}
+	for _, tt := range tests {
+		if got := conflictClause(tt.policy); got != tt.want {
+			t.Errorf("conflictClause(%s) = %q, quer %q", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestCheckDocumentMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		app      config.AppConfig
+		strategy Strategy
+		wantErr  bool
+	}{
+		{"stream-goroutines", config.AppConfig{OnConflict: ConflictSkip, WriteMethod: WriteInsert}, StreamWorkers, false},
+		{"simple", config.AppConfig{OnConflict: ConflictOverwrite}, InMemory, false},
+		{"partitioned", config.AppConfig{OnConflict: ConflictSkip}, Partitioned, true},
+		{"resume", config.AppConfig{OnConflict: ConflictSkip, Resume: true}, Stream, true},
+		{"incremental", config.AppConfig{OnConflict: ConflictSkip, Incremental: true}, Stream, true},
+		{"newer", config.AppConfig{OnConflict: ConflictNewer}, Stream, true},
+		{"copy", config.AppConfig{OnConflict: ConflictSkip, WriteMethod: WriteCopy}, Stream, true},
+		{"política desconhecida", config.AppConfig{OnConflict: "merge"}, Stream, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDocumentMode(&tt.app, ModeJSONB, tt.strategy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkDocumentMode() = %v, quer erro %t", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"migration-go/internal/config"
+	"migration-go/internal/database"
+	"migration-go/internal/deadletter"
+	"migration-go/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// dryRun executa a estratégia lendo e decodificando tudo do MongoDB, mas com um
+// destino que apenas valida os produtos e imprime os comandos que seriam
+// enviados ao PostgreSQL. Documentos rejeitados são impressos em vez de irem
+// para a dead-letter configurada.
+func dryRun(ctx context.Context, cfg *config.Config, strategy Strategy) (*Stats, error) {
+	if cfg.App.Resume || cfg.App.Incremental {
+		return nil, errors.New("--dry-run simula uma carga completa e não combina com --resume ou --incremental")
+	}
+	sink, err := newDryRunSink(cfg.App.WriteMethod, cfg.App.OnConflict)
+	if err != nil {
+		return nil, err
+	}
+
+	mongoManager := database.NewMongoManager(&cfg.MongoDB)
+	if err := mongoManager.Connect(ctx); err != nil {
+		return nil, err
+	}
+	defer mongoManager.Disconnect(context.Background())
+	fmt.Println("Conectado ao MongoDB! (dry-run: o PostgreSQL não será acessado)")
+
+	fmt.Println("[dry-run] CREATE TABLE IF NOT EXISTS products (" + strings.Join(productColumns, ", ") + ");")
+	if cfg.App.Truncate {
+		fmt.Println("[dry-run] TRUNCATE TABLE products;")
+	}
+
+	engine, err := newEngine(cfg, mongoManager.GetCollection(), sink, dryRunRejects{})
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Simulando a migração MongoDB -> PostgreSQL (estratégia %s, escrita %s, conflitos %s)...\n",
+		strategy.Name(), cfg.App.WriteMethod, cfg.App.OnConflict)
+	startTime := time.Now()
+	err = strategy.Migrate(ctx, engine)
+
+	stats := engine.stats()
+	stats.Strategy = strategy.Name()
+	stats.Duration = time.Since(startTime)
+	fmt.Printf("Projeção: %d linhas seriam gravadas em products; %d documentos seriam rejeitados.\n",
+		stats.Written, stats.Failed)
+	if err != nil {
+		return stats, fmt.Errorf("simulação %s interrompida: %w", strategy.Name(), err)
+	}
+	return stats, nil
+}
+
+// dryRunSink valida cada lote e imprime os comandos que o método de escrita
+// configurado enviaria, sem abrir conexão com o PostgreSQL
+type dryRunSink struct {
+	method string
+	policy string
+}
+
+func newDryRunSink(method, policy string) (dryRunSink, error) {
+	if _, err := newSink(method, policy, nil); err != nil {
+		return dryRunSink{}, err
+	}
+	return dryRunSink{method: method, policy: policy}, nil
+}
+
+func (s dryRunSink) NewWriter(ctx context.Context) (Writer, error) {
+	return s, nil
+}
+
+// Write falha o lote inteiro no primeiro produto inválido, como o PostgreSQL
+// faria; o Engine então regrava produto a produto e rejeita só os inválidos
+func (s dryRunSink) Write(ctx context.Context, batch []models.Product) (int64, error) {
+	for _, p := range batch {
+		if err := validateProduct(p); err != nil {
+			return 0, err
+		}
+	}
+
+	ids := fmt.Sprintf("/* %d linhas, IDs %d..%d */", len(batch), batch[0].ID, batch[len(batch)-1].ID)
+	cols := strings.Join(productColumns, ", ")
+	var sb strings.Builder
+	switch {
+	case s.method == WriteCopy && s.policy == ConflictError:
+		fmt.Fprintf(&sb, "[dry-run] COPY products (%s) FROM STDIN %s;\n", cols, ids)
+	case s.method == WriteCopy:
+		sb.WriteString("[dry-run] CREATE TEMP TABLE products_stage (LIKE products INCLUDING DEFAULTS) ON COMMIT DROP;\n")
+		fmt.Fprintf(&sb, "[dry-run] COPY products_stage (%s) FROM STDIN %s;\n", cols, ids)
+		fmt.Fprintf(&sb, "[dry-run] INSERT INTO products (%s) SELECT %s FROM products_stage%s;\n", cols, cols, conflictClause(s.policy))
+	default:
+		for start := 0; start < len(batch); start += maxRowsPerStatement {
+			chunk := batch[start:min(start+maxRowsPerStatement, len(batch))]
+			fmt.Fprintf(&sb, "[dry-run] INSERT INTO products (%s) VALUES /* %d linhas, IDs %d..%d */%s;\n",
+				cols, len(chunk), chunk[0].ID, chunk[len(chunk)-1].ID, conflictClause(s.policy))
+		}
+	}
+	fmt.Print(sb.String())
+	return int64(len(batch)), nil
+}
+
+func (s dryRunSink) Close() error {
+	return nil
+}
+
+// dryRunDocuments simula um modo genérico: lê e converte tudo do MongoDB e
+// imprime o DDL e os INSERTs que seriam enviados ao PostgreSQL
+func dryRunDocuments(ctx context.Context, cfg *config.Config, strategy Strategy, target *tableTarget) (*Stats, error) {
+	mongoManager := database.NewMongoManager(&cfg.MongoDB)
+	if err := mongoManager.Connect(ctx); err != nil {
+		return nil, err
+	}
+	defer mongoManager.Disconnect(context.Background())
+	fmt.Println("Conectado ao MongoDB! (dry-run: o PostgreSQL não será acessado)")
+
+	writer := newDryRunTableWriter(target, cfg.App.OnConflict)
+	engine, err := documentEngine(cfg, mongoManager.GetCollection(), target, func(ctx context.Context) (rowWriter[docRow], error) {
+		return writer, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	engine.deadLetter = dryRunRejects{}
+
+	fmt.Printf("[dry-run] %s\n", strings.TrimSpace(target.ddl))
+	if cfg.App.Truncate {
+		fmt.Printf("[dry-run] TRUNCATE TABLE %s;\n", pq.QuoteIdentifier(target.table))
+	}
+
+	fmt.Printf("Simulando a migração MongoDB -> PostgreSQL (modo %s, tabela %s, estratégia %s, conflitos %s)...\n",
+		target.mode, target.table, strategy.Name(), cfg.App.OnConflict)
+	startTime := time.Now()
+	err = strategy.Migrate(ctx, engine)
+
+	stats := engine.stats()
+	stats.Strategy = strategy.Name()
+	stats.Duration = time.Since(startTime)
+	fmt.Printf("Projeção: %d linhas seriam gravadas em %s; %d documentos seriam rejeitados.\n",
+		stats.Written, target.table, stats.Failed)
+	if err != nil {
+		return stats, fmt.Errorf("simulação %s interrompida: %w", target.mode, err)
+	}
+	return stats, nil
+}
+
+// dryRunTableWriter imprime os INSERTs de um destino genérico sem executá-los
+type dryRunTableWriter struct {
+	table        string
+	columns      string
+	conflict     string
+	perStatement int
+}
+
+func newDryRunTableWriter(target *tableTarget, policy string) dryRunTableWriter {
+	table, columns, key := target.quoted()
+	return dryRunTableWriter{
+		table:        table,
+		columns:      strings.Join(columns, ", "),
+		conflict:     upsertClause(key, columns, policy),
+		perStatement: maxStatementParams / len(columns),
+	}
+}
+
+func (w dryRunTableWriter) Write(ctx context.Context, batch []docRow) (int64, error) {
+	var sb strings.Builder
+	for start := 0; start < len(batch); start += w.perStatement {
+		fmt.Fprintf(&sb, "[dry-run] INSERT INTO %s (%s) VALUES /* %d linhas */%s;\n",
+			w.table, w.columns, min(w.perStatement, len(batch)-start), w.conflict)
+	}
+	fmt.Print(sb.String())
+	return int64(len(batch)), nil
+}
+
+func (w dryRunTableWriter) Close() error {
+	return nil
+}
+
+// dryRunRejects imprime os documentos rejeitados no lugar da dead-letter
+type dryRunRejects struct{}
+
+func (dryRunRejects) Write(ctx context.Context, entry deadletter.Entry) error {
+	fmt.Printf("[dry-run] rejeitado na etapa %s: %s\n          %s\n", entry.Stage, entry.Error, entry.Document)
+	return nil
+}
+
+func (dryRunRejects) Close() error {
+	return nil
+}
@@ -0,0 +1,125 @@
+package migrate
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+
+	"migration-go/internal/deadletter"
+	"migration-go/internal/models"
+	"migration-go/internal/transform"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// progressEvery define a cada quantos registros o progresso é exibido
+const progressEvery = 5000
+
+// Engine concentra a configuração e os contadores compartilhados pelas
+// estratégias; leitura e escrita ficam no pipeline do tipo de linha do modo
+type Engine struct {
+	source      string
+	collection  *mongo.Collection
+	filter      bson.M
+	query       sourceQuery
+	sink        Sink
+	deadLetter  deadletter.Sink
+	checkpoint  checkpointer
+	checkpoints *checkpointStore
+	seen        *latestSeen
+	transform   transform.Chain
+	retry       retryPolicy
+	workers     int
+	batchSize   int
+
+	partitionCount  int
+	partitionMethod string
+
+	read    atomic.Int64
+	written atomic.Int64
+	failed  atomic.Int64
+	retries atomic.Int64
+
+	deadLettered atomic.Int64
+	filtered     atomic.Int64
+
+	outcomes   outcomeLog
+	partitions []*partitionProgress
+
+	// flow é o pipeline usado pelas estratégias; products é o mesmo pipeline
+	// no modo products e nil nos modos genéricos (jsonb e mapping)
+	flow     flow
+	products *pipeline[models.Product]
+}
+
+// Workers retorna quantos workers de escrita a estratégia pode usar
+func (e *Engine) Workers() int {
+	return e.workers
+}
+
+// sourceFilter retorna o filtro aplicado a toda leitura da origem: o filtro
+// interno (retomada ou modo incremental) combinado com o configurado
+func (e *Engine) sourceFilter() bson.M {
+	return e.query.where(e.filter)
+}
+
+// reject envia um documento para a dead-letter, se configurada
+func (e *Engine) reject(ctx context.Context, stage string, doc interface{}, cause error) bool {
+	if e.deadLetter == nil {
+		return false
+	}
+
+	entry := deadletter.NewEntry(e.source, stage, doc, cause)
+	if err := e.deadLetter.Write(context.WithoutCancel(ctx), entry); err != nil {
+		log.Printf("Erro ao gravar documento na dead-letter: %v", err)
+		return false
+	}
+	e.deadLettered.Add(1)
+	return true
+}
+
+// productPipeline é o pipeline do modo products: a leitura segue product_id,
+// cada registro é decodificado em models.Product e os lotes vão para o Sink
+func productPipeline(e *Engine) *pipeline[models.Product] {
+	return &pipeline[models.Product]{
+		Engine: e,
+		key:    "product_id",
+		convert: func(rec bson.Raw) (models.Product, error) {
+			var p models.Product
+			if err := bson.Unmarshal(rec, &p); err != nil {
+				return p, err
+			}
+			if e.seen != nil {
+				e.seen.observe(p)
+			}
+			return p, nil
+		},
+		id:       func(p models.Product) int { return p.ID },
+		document: func(p models.Product) interface{} { return p },
+		newWriter: func(ctx context.Context) (rowWriter[models.Product], error) {
+			w, err := e.sink.NewWriter(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return w, nil
+		},
+	}
+}
+
+// stats retorna um retrato dos contadores atuais
+func (e *Engine) stats() *Stats {
+	return &Stats{
+		Read:       e.read.Load(),
+		Written:    e.written.Load(),
+		Failed:     e.failed.Load(),
+		Retries:    e.retries.Load(),
+		Batches:    e.outcomes.snapshot(),
+		Checkpoint: e.checkpoint.current(),
+		Watermark:  e.seen.current(),
+		Partitions: e.partitionStats(),
+
+		DeadLettered: e.deadLettered.Load(),
+		Filtered:     e.filtered.Load(),
+	}
+}
@@ -0,0 +1,91 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"migration-go/internal/config"
+	"migration-go/internal/models"
+)
+
+func TestLatestSeen(t *testing.T) {
+	scanStart := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	since := scanStart.Add(-24 * time.Hour)
+	ceiling := scanStart.Add(-watermarkSkew)
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		products []models.Product
+		want     time.Time
+	}{
+		{"nada lido mantém a marca anterior", since, nil, since},
+		{"primeira sincronização sem documentos", time.Time{}, nil, time.Time{}},
+		{
+			"maior created_at ou updated_at",
+			since,
+			[]models.Product{
+				{CreatedAt: since.Add(time.Hour)},
+				{CreatedAt: since.Add(2 * time.Hour), UpdatedAt: since.Add(3 * time.Hour)},
+				{CreatedAt: since.Add(90 * time.Minute)},
+			},
+			since.Add(3 * time.Hour),
+		},
+		{
+			"alteração durante a varredura não passa do início da leitura",
+			since,
+			[]models.Product{
+				{CreatedAt: since.Add(time.Hour)},
+				{CreatedAt: since, UpdatedAt: scanStart.Add(30 * time.Second)},
+			},
+			ceiling,
+		},
+		{
+			"instante dentro da folga de relógio é limitado",
+			since,
+			[]models.Product{{CreatedAt: scanStart.Add(-30 * time.Second)}},
+			ceiling,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := newLatestSeen(tt.start, scanStart)
+			for _, p := range tt.products {
+				seen.observe(p)
+			}
+			if got := seen.current(); !got.Equal(tt.want) {
+				t.Errorf("current() = %s, quer %s", got, tt.want)
+			}
+		})
+	}
+
+	var none *latestSeen
+	if got := none.current(); !got.IsZero() {
+		t.Errorf("current() sem modo incremental = %s, quer zero", got)
+	}
+}
+
+func TestResumeHint(t *testing.T) {
+	tests := []struct {
+		name string
+		app  config.AppConfig
+		want string
+	}{
+		{"carga completa", config.AppConfig{}, "--resume a partir do product_id 42"},
+		{"retomada", config.AppConfig{Resume: true}, "--resume a partir do product_id 42"},
+		{"incremental", config.AppConfig{Incremental: true}, "--incremental"},
+		{"ordenação configurada", config.AppConfig{SourceSort: "-name"}, "checkpoint não é salvo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resumeHint(&config.Config{App: tt.app}, &Stats{Checkpoint: 42})
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("resumeHint = %q, quer conter %q", got, tt.want)
+			}
+			if tt.app.Incremental && strings.Contains(got, "--resume") {
+				t.Errorf("resumeHint no modo incremental sugere --resume: %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"migration-go/internal/config"
+	"migration-go/internal/database"
+	"migration-go/internal/deadletter"
+	"migration-go/internal/job"
+	"migration-go/internal/mapping"
+)
+
+// StepStats é o resultado de uma etapa do job
+type StepStats struct {
+	Name       string
+	Collection string
+	Table      string
+	Workers    int
+
+	// Stats é nil se a etapa não chegou a rodar
+	Stats    *Stats
+	Err      error
+	Skipped  bool
+	Duration time.Duration
+}
+
+// JobStats resume uma execução de job, etapa por etapa
+type JobStats struct {
+	Workers  int
+	Steps    []StepStats
+	Duration time.Duration
+}
+
+// Failed retorna as etapas que terminaram com erro ou foram puladas porque
+// uma dependência falhou
+func (s JobStats) Failed() []StepStats {
+	var out []StepStats
+	for _, step := range s.Steps {
+		if step.Err != nil || step.Skipped {
+			out = append(out, step)
+		}
+	}
+	return out
+}
+
+// String formata o resumo combinado para exibição no terminal
+func (s JobStats) String() string {
+	var b strings.Builder
+	var total Stats
+	ok, failed, skipped := 0, 0, 0
+	for _, step := range s.Steps {
+		fmt.Fprintf(&b, "  %s (%s -> %s, workers=%d): ", step.Name, step.Collection, step.Table, step.Workers)
+		switch {
+		case step.Skipped:
+			skipped++
+			fmt.Fprintf(&b, "ignorada: %v\n", step.Err)
+			continue
+		case step.Err != nil:
+			failed++
+			fmt.Fprintf(&b, "ERRO após %s: %v\n", step.Duration, step.Err)
+		default:
+			ok++
+			fmt.Fprintf(&b, "ok em %s\n", step.Duration)
+		}
+		if step.Stats != nil {
+			fmt.Fprintf(&b, "    %s\n", step.Stats)
+			total.Read += step.Stats.Read
+			total.Written += step.Stats.Written
+			total.Failed += step.Stats.Failed
+			total.Retries += step.Stats.Retries
+			total.DeadLettered += step.Stats.DeadLettered
+			total.Filtered += step.Stats.Filtered
+		}
+	}
+
+	return fmt.Sprintf("Job: %d etapas (ok=%d erro=%d ignoradas=%d) com até %d workers em %s\n%stotal: lidos=%d gravados=%d falhas=%d dead-letter=%d filtrados=%d retentativas=%d",
+		len(s.Steps), ok, failed, skipped, s.Workers, s.Duration, b.String(),
+		total.Read, total.Written, total.Failed, total.DeadLettered, total.Filtered, total.Retries)
+}
+
+// plannedStep é uma etapa com a configuração e a estratégia já resolvidas
+type plannedStep struct {
+	job.Step
+	cfg      *config.Config
+	strategy Strategy
+}
+
+// RunJob executa as etapas do job respeitando as dependências. Etapas cujas
+// dependências já terminaram rodam em paralelo, desde que caibam no orçamento
+// de workers; uma etapa com dependência que falhou é pulada. O resumo traz
+// todas as etapas, mesmo quando alguma falha.
+func RunJob(ctx context.Context, cfg *config.Config, j *job.Job) (*JobStats, error) {
+	budget := j.Workers
+	if budget == 0 {
+		budget = max(cfg.App.NumWorkers, 1)
+	}
+
+	// Toda etapa é validada antes de qualquer uma começar
+	steps := make([]plannedStep, len(j.Steps))
+	for i, s := range j.Steps {
+		planned, err := planStep(cfg, s, budget)
+		if err != nil {
+			return nil, fmt.Errorf("etapa %s: %w", s.Name, err)
+		}
+		steps[i] = planned
+	}
+
+	levels, err := j.Levels()
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.App.DryRun {
+		if err := prepareJobState(ctx, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Printf("Job com %d etapas e orçamento de %d workers:\n", len(steps), budget)
+	for i, level := range levels {
+		fmt.Printf("  nível %d: %s\n", i+1, strings.Join(level, ", "))
+	}
+
+	stats := &JobStats{Workers: budget, Steps: make([]StepStats, len(steps))}
+	done := make(map[string]chan struct{}, len(steps))
+	index := make(map[string]int, len(steps))
+	for i, s := range steps {
+		done[s.Name] = make(chan struct{})
+		index[s.Name] = i
+	}
+
+	workers := newWorkerBudget(budget)
+	startTime := time.Now()
+	var wg sync.WaitGroup
+	for i, s := range steps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[s.Name])
+
+			result := &stats.Steps[i]
+			*result = StepStats{
+				Name:       s.Name,
+				Collection: s.cfg.MongoDB.Collection,
+				Table:      s.Table,
+				Workers:    s.cfg.App.NumWorkers,
+			}
+
+			// Cada etapa só lê o resultado das dependências depois que o
+			// canal delas foi fechado
+			for _, dep := range s.DependsOn {
+				<-done[dep]
+				if d := stats.Steps[index[dep]]; d.Err != nil || d.Skipped {
+					result.Skipped = true
+					result.Err = fmt.Errorf("dependência %s não concluída", dep)
+					fmt.Printf("Etapa %s ignorada: dependência %s não concluída.\n", s.Name, dep)
+					return
+				}
+			}
+
+			if err := workers.acquire(ctx, s.cfg.App.NumWorkers); err != nil {
+				result.Skipped = true
+				result.Err = fmt.Errorf("job interrompido antes do início: %w", err)
+				return
+			}
+			defer workers.release(s.cfg.App.NumWorkers)
+
+			fmt.Printf("Etapa %s iniciada (%s -> %s, %d workers).\n", s.Name, result.Collection, result.Table, result.Workers)
+			start := time.Now()
+			result.Stats, result.Err = Run(ctx, s.cfg, s.strategy)
+			result.Duration = time.Since(start)
+			if result.Err != nil {
+				fmt.Printf("Etapa %s falhou após %s: %v\n", s.Name, result.Duration, result.Err)
+			} else {
+				fmt.Printf("Etapa %s concluída em %s.\n", s.Name, result.Duration)
+			}
+		}()
+	}
+	wg.Wait()
+	stats.Duration = time.Since(startTime)
+
+	if failed := stats.Failed(); len(failed) > 0 {
+		names := make([]string, len(failed))
+		for i, f := range failed {
+			names[i] = f.Name
+		}
+		return stats, fmt.Errorf("%d de %d etapas não concluídas: %s", len(failed), len(steps), strings.Join(names, ", "))
+	}
+	return stats, nil
+}
+
+// planStep monta a configuração da etapa a partir da configuração do
+// migrator. As opções de origem e destino vêm apenas da etapa; as de escrita,
+// retentativa e dead-letter são herdadas.
+func planStep(cfg *config.Config, s job.Step, budget int) (plannedStep, error) {
+	c := *cfg
+	c.MongoDB.Collection = s.Collection
+	c.App.Mode = s.Mode
+	if c.App.Mode == "" {
+		c.App.Mode = ModeProducts
+	}
+	c.App.TargetTable = s.Table
+	c.App.MappingFile = s.Mapping
+	c.App.Promote = s.Promote
+	c.App.Transforms = s.Transform
+	c.App.SourceFilter = string(s.Filter)
+	c.App.SourceProjection = s.Projection
+	c.App.SourceSort = s.Sort
+	c.App.SourceLimit = s.Limit
+
+	c.App.NumWorkers = s.Workers
+	if c.App.NumWorkers == 0 {
+		c.App.NumWorkers = max(cfg.App.NumWorkers, 1)
+	}
+	c.App.NumWorkers = min(c.App.NumWorkers, budget)
+
+	// Etapas paralelas não compartilham o arquivo da dead-letter
+	if c.App.DeadLetter == deadletter.KindFile {
+		ext := filepath.Ext(c.App.DeadLetterFile)
+		c.App.DeadLetterFile = strings.TrimSuffix(c.App.DeadLetterFile, ext) + "." + s.Name + ext
+	}
+
+	key := "_id"
+	switch c.App.Mode {
+	case ModeProducts:
+		if s.Table != "" && s.Table != "products" {
+			return plannedStep{}, fmt.Errorf("o modo %s grava sempre na tabela products", ModeProducts)
+		}
+		s.Table = "products"
+		key = "product_id"
+	case ModeJSONB:
+		if s.Table == "" {
+			s.Table = s.Collection
+		}
+	case ModeMapping:
+		if s.Mapping == "" {
+			return plannedStep{}, fmt.Errorf("o modo %s exige mapping", ModeMapping)
+		}
+		m, err := mapping.Load(s.Mapping)
+		if err != nil {
+			return plannedStep{}, err
+		}
+		if s.Collection != "" && m.Collection != "" && s.Collection != m.Collection {
+			return plannedStep{}, fmt.Errorf("collection %s difere da declarada no mapeamento (%s)", s.Collection, m.Collection)
+		}
+		if c.MongoDB.Collection == "" {
+			c.MongoDB.Collection = m.Collection
+		}
+		if s.Table == "" {
+			s.Table = m.Table
+		}
+	default:
+		return plannedStep{}, fmt.Errorf("modo desconhecido %q (disponíveis: %s, %s, %s)", c.App.Mode, ModeProducts, ModeJSONB, ModeMapping)
+	}
+	if c.MongoDB.Collection == "" {
+		return plannedStep{}, errors.New("collection é obrigatório (no modo mapping, pode vir do mapeamento)")
+	}
+	if _, err := newSourceQuery(&c.App, key); err != nil {
+		return plannedStep{}, err
+	}
+
+	name := s.Strategy
+	if name == "" {
+		name = StreamWorkers.Name()
+	}
+	strategy, err := Lookup(name)
+	if err != nil {
+		return plannedStep{}, err
+	}
+	if c.App.Mode != ModeProducts {
+		if err := checkDocumentMode(&c.App, c.App.Mode, strategy); err != nil {
+			return plannedStep{}, err
+		}
+	}
+	return plannedStep{Step: s, cfg: &c, strategy: strategy}, nil
+}
+
+// prepareJobState cria as tabelas de estado compartilhadas pelas etapas antes
+// que elas rodem em paralelo: CREATE TABLE IF NOT EXISTS simultâneos da mesma
+// tabela podem falhar no PostgreSQL
+func prepareJobState(ctx context.Context, cfg *config.Config) error {
+	pgManager := database.NewPostgresManager(&cfg.Postgres)
+	if err := pgManager.Connect(); err != nil {
+		return err
+	}
+	defer pgManager.Close()
+	db := pgManager.GetDB()
+
+	if err := (&checkpointStore{db: db}).setup(ctx); err != nil {
+		return fmt.Errorf("erro ao criar a tabela de checkpoints: %w", err)
+	}
+	if err := (&syncStateStore{db: db}).setup(ctx); err != nil {
+		return fmt.Errorf("erro ao criar a tabela de marcas d'água: %w", err)
+	}
+	if cfg.App.DeadLetter == deadletter.KindTable {
+		sink, err := deadletter.OpenTable(ctx, db)
+		if err != nil {
+			return err
+		}
+		sink.Close()
+	}
+	return nil
+}
+
+// workerBudget reserva workers de um total compartilhado entre as etapas
+type workerBudget struct {
+	mu   sync.Mutex
+	free int
+	wake chan struct{}
+}
+
+func newWorkerBudget(n int) *workerBudget {
+	return &workerBudget{free: n, wake: make(chan struct{})}
+}
+
+// acquire espera até haver n workers livres ou o contexto ser cancelado
+func (b *workerBudget) acquire(ctx context.Context, n int) error {
+	for {
+		b.mu.Lock()
+		if b.free >= n {
+			b.free -= n
+			b.mu.Unlock()
+			return nil
+		}
+		wake := b.wake
+		b.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release devolve n workers e acorda as etapas que aguardam
+func (b *workerBudget) release(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.free += n
+	close(b.wake)
+	b.wake = make(chan struct{})
+}
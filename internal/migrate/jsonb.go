@@ -0,0 +1,180 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"migration-go/internal/config"
+	"migration-go/internal/mapping"
+
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// promotedColumn é uma coluna extraída do documento por um caminho com pontos
+// (ex.: customer.address.city) e gravada ao lado do JSONB
+type promotedColumn struct {
+	column string
+	path   []string
+	pgType string
+}
+
+// parsePromotions lê especificações no formato coluna=caminho[:TIPO],
+// separadas por vírgula. Sem tipo, a coluna é TEXT.
+func parsePromotions(spec string) ([]promotedColumn, error) {
+	var out []promotedColumn
+	for _, item := range splitOutsideParens(spec) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		column, rest, ok := strings.Cut(item, "=")
+		if !ok || column == "" || rest == "" {
+			return nil, fmt.Errorf("coluna promovida inválida %q (formato: coluna=caminho[:TIPO])", item)
+		}
+		path, pgType, _ := strings.Cut(rest, ":")
+		if pgType == "" {
+			pgType = "TEXT"
+		}
+		if !mapping.ValidType(pgType) {
+			return nil, fmt.Errorf("tipo inválido %q na coluna promovida %s", pgType, column)
+		}
+		out = append(out, promotedColumn{column: strings.TrimSpace(column), path: strings.Split(path, "."), pgType: pgType})
+	}
+	return out, nil
+}
+
+// splitOutsideParens separa por vírgula sem quebrar tipos como NUMERIC(10, 2)
+func splitOutsideParens(s string) []string {
+	var out []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				out = append(out, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(out, s[start:])
+}
+
+// jsonbTarget monta o destino genérico: cada documento vira uma linha
+// (source_id, doc, migrated_at), mais as colunas promovidas configuradas
+func jsonbTarget(cfg *config.Config) (*tableTarget, error) {
+	table := cfg.App.TargetTable
+	if table == "" {
+		table = cfg.MongoDB.Collection
+	}
+	promoted, err := parsePromotions(cfg.App.Promote)
+	if err != nil {
+		return nil, err
+	}
+
+	quotedTable := pq.QuoteIdentifier(table)
+	ddl := fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS %s (
+		source_id TEXT PRIMARY KEY,
+		doc JSONB NOT NULL,
+		migrated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+	);`, quotedTable)
+	columns := []string{"source_id", "doc", "migrated_at"}
+	for _, p := range promoted {
+		// Colunas promovidas novas são acrescentadas a tabelas já existentes
+		ddl += fmt.Sprintf("\n\tALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s;", quotedTable, pq.QuoteIdentifier(p.column), p.pgType)
+		columns = append(columns, p.column)
+	}
+
+	return &tableTarget{
+		mode:    ModeJSONB,
+		table:   table,
+		key:     "source_id",
+		columns: columns,
+		ddl:     ddl,
+		row: func(doc bson.Raw) ([]interface{}, error) {
+			id, err := doc.LookupErr("_id")
+			if err != nil {
+				return nil, errors.New("documento sem _id")
+			}
+			sourceID, err := rawText(id)
+			if err != nil {
+				return nil, err
+			}
+			ext, err := bson.MarshalExtJSON(doc, false, false)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao converter o documento %s para JSON: %w", sourceID, err)
+			}
+
+			values := []interface{}{sourceID, string(ext), time.Now()}
+			for _, p := range promoted {
+				v, err := promotedValue(doc, p.path)
+				if err != nil {
+					return nil, fmt.Errorf("documento %s, coluna %s: %w", sourceID, p.column, err)
+				}
+				values = append(values, v)
+			}
+			return values, nil
+		},
+	}, nil
+}
+
+// promotedValue extrai o valor no caminho informado. Campos ausentes ou nulos
+// viram NULL; documentos e arrays viram JSON.
+func promotedValue(doc bson.Raw, path []string) (interface{}, error) {
+	v, err := doc.LookupErr(path...)
+	if errors.Is(err, bsoncore.ErrElementNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch v.Type {
+	case bson.TypeNull, bson.TypeUndefined:
+		return nil, nil
+	case bson.TypeString:
+		return v.StringValue(), nil
+	case bson.TypeInt32:
+		return int64(v.Int32()), nil
+	case bson.TypeInt64:
+		return v.Int64(), nil
+	case bson.TypeDouble:
+		return v.Double(), nil
+	case bson.TypeBoolean:
+		return v.Boolean(), nil
+	case bson.TypeDateTime:
+		return v.Time(), nil
+	default:
+		return rawText(v)
+	}
+}
+
+// rawText converte um valor BSON em texto: ObjectID em hexadecimal, strings
+// como estão e os demais tipos em Extended JSON relaxado
+func rawText(v bson.RawValue) (string, error) {
+	switch v.Type {
+	case bson.TypeObjectID:
+		return v.ObjectID().Hex(), nil
+	case bson.TypeString:
+		return v.StringValue(), nil
+	case bson.TypeInt32:
+		return strconv.FormatInt(int64(v.Int32()), 10), nil
+	case bson.TypeInt64:
+		return strconv.FormatInt(v.Int64(), 10), nil
+	case bson.TypeDouble:
+		return strconv.FormatFloat(v.Double(), 'f', -1, 64), nil
+	case bson.TypeDecimal128:
+		return v.Decimal128().String(), nil
+	}
+	return mapping.ExtJSON(v)
+}
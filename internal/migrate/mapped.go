@@ -0,0 +1,45 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+
+	"migration-go/internal/config"
+	"migration-go/internal/mapping"
+)
+
+// ModeMapping grava as colunas declaradas em um arquivo de mapeamento
+const ModeMapping = "mapping"
+
+// runMapping migra a coleção para a tabela descrita no arquivo de mapeamento.
+// O DDL, a conversão de cada documento e o INSERT vêm do arquivo; a coleção
+// declarada nele, se houver, substitui a configurada, e TargetTable, se
+// informada, substitui a tabela do arquivo.
+func runMapping(ctx context.Context, cfg *config.Config, strategy Strategy) (*Stats, error) {
+	if cfg.App.MappingFile == "" {
+		return nil, fmt.Errorf("o modo %s exige um arquivo de mapeamento (--mapping ou MAPPING_FILE)", ModeMapping)
+	}
+	m, err := mapping.Load(cfg.App.MappingFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Collection != "" && m.Collection != cfg.MongoDB.Collection {
+		mapped := *cfg
+		mapped.MongoDB.Collection = m.Collection
+		cfg = &mapped
+	}
+
+	if cfg.App.TargetTable != "" {
+		m.Table = cfg.App.TargetTable
+	}
+
+	return runDocuments(ctx, cfg, strategy, &tableTarget{
+		mode:    ModeMapping,
+		table:   m.Table,
+		key:     m.Key(),
+		columns: m.Columns(),
+		ddl:     m.DDL(),
+		row:     m.Row,
+	})
+}
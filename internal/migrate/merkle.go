@@ -0,0 +1,267 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"migration-go/internal/config"
+	"migration-go/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// rangeDigest resume um intervalo de product_id: a quantidade de produtos e a
+// soma, em duas metades de 32 bits, do MD5 de cada produto. Por ser uma soma,
+// não depende da ordem de leitura e pode ser calculado dentro de cada banco.
+type rangeDigest struct {
+	Count int64 `bson:"count"`
+	Hi    int64 `bson:"hi"`
+	Lo    int64 `bson:"lo"`
+}
+
+// rowDigestJS calcula no MongoDB o MD5 de um produto e o divide em duas
+// metades de 32 bits. A string é a mesma montada por rowDigestSQL no PostgreSQL.
+// O preço segue priceText: toFixed(2) arredondaria o valor binário (0.125 ->
+// "0.12"), enquanto o PostgreSQL arredonda a menor representação decimal.
+const rowDigestJS = `function(id, name, description, price, createdAt) {
+	var v = Number(price || 0);
+	var text = String(Math.abs(v));
+	if (text.indexOf("e-") >= 0) {
+		text = "0";
+	}
+	var dot = text.indexOf(".");
+	var intPart = dot < 0 ? text : text.substring(0, dot);
+	var frac = dot < 0 ? "" : text.substring(dot + 1);
+	var digits = (intPart + (frac + "00").substring(0, 2)).split("");
+	if (frac.length > 2 && frac.charAt(2) >= "5") {
+		var i = digits.length - 1;
+		for (; i >= 0 && digits[i] === "9"; i--) {
+			digits[i] = "0";
+		}
+		if (i < 0) {
+			digits.unshift("1");
+		} else {
+			digits[i] = String(Number(digits[i]) + 1);
+		}
+	}
+	var n = digits.length - 2;
+	var priceText = digits.slice(0, n).join("") + "." + digits.slice(n).join("");
+	if (v < 0 && /[1-9]/.test(digits.join(""))) {
+		priceText = "-" + priceText;
+	}
+
+	var s = [
+		String(Number(id)),
+		name || "",
+		description || "",
+		priceText,
+		createdAt ? String(createdAt.getTime()) : ""
+	].join("\u001f");
+	var h = hex_md5(s);
+	return [parseInt(h.substring(0, 8), 16), parseInt(h.substring(8, 16), 16)];
+}`
+
+// rowDigestSQL é o equivalente de rowDigestJS para um intervalo da tabela products
+const rowDigestSQL = `
+	SELECT COUNT(*),
+	       COALESCE(SUM(('x' || substr(h, 1, 8))::bit(32)::bigint), 0),
+	       COALESCE(SUM(('x' || substr(h, 9, 8))::bit(32)::bigint), 0)
+	FROM (
+		SELECT md5(concat_ws(chr(31),
+			id::text,
+			COALESCE(name, ''),
+			COALESCE(description, ''),
+			price::numeric(10, 2)::text,
+			COALESCE((floor(extract(epoch FROM created_at) * 1000)::bigint)::text, '')
+		)) AS h
+		FROM products
+		WHERE id BETWEEN $1 AND $2
+	) AS t`
+
+// merkleVerifier compara intervalos de product_id pelo digest e só desce aos
+// produtos nos intervalos pequenos cujo digest diverge
+type merkleVerifier struct {
+	collection *mongo.Collection
+	pg         *database.PostgresManager
+	leafSize   int64
+	report     *VerifyReport
+	ranges     int64
+}
+
+// VerifyMerkle compara origem e destino por intervalos de product_id. Cada
+// intervalo é resumido pelos próprios bancos (aggregation no MongoDB, agregado
+// SQL no PostgreSQL); intervalos com resumos diferentes são divididos ao meio
+// até ficarem com no máximo leafSize produtos, quando então são comparados
+// produto a produto como no Verify. Exige JavaScript no servidor do MongoDB ($function).
+func VerifyMerkle(ctx context.Context, cfg *config.Config, leafSize int) (*VerifyReport, error) {
+	if err := wholeCollection(&cfg.App, "verify"); err != nil {
+		return nil, err
+	}
+
+	pgManager, mongoManager, disconnect, err := connect(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer disconnect()
+
+	startTime := time.Now()
+	v := &merkleVerifier{
+		collection: mongoManager.GetCollection(),
+		pg:         pgManager,
+		leafSize:   int64(max(leafSize, 1)),
+		report:     &VerifyReport{Source: sourceKey(cfg)},
+	}
+
+	lo, hi, found, err := v.bounds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		mongoDigest, pgDigest, err := v.digests(ctx, lo, hi)
+		if err != nil {
+			return nil, err
+		}
+		v.report.Documents, v.report.Rows = mongoDigest.Count, pgDigest.Count
+		fmt.Printf("MongoDB: %d documentos | PostgreSQL: %d linhas (product_id %d..%d)\n",
+			mongoDigest.Count, pgDigest.Count, lo, hi)
+
+		if err := v.verify(ctx, lo, hi, mongoDigest, pgDigest); err != nil {
+			return nil, err
+		}
+	}
+
+	v.report.Duration = time.Since(startTime)
+	fmt.Printf("%d intervalos comparados.\n", v.ranges)
+	return v.report, nil
+}
+
+// verify compara um intervalo cujos digests já foram calculados
+func (v *merkleVerifier) verify(ctx context.Context, lo, hi int, mongoDigest, pgDigest rangeDigest) error {
+	v.ranges++
+	if mongoDigest == pgDigest {
+		return nil
+	}
+	if lo == hi || max(mongoDigest.Count, pgDigest.Count) <= v.leafSize {
+		return v.compareLeaf(ctx, lo, hi)
+	}
+
+	mid := lo + (hi-lo)/2
+	for _, r := range [][2]int{{lo, mid}, {mid + 1, hi}} {
+		mongoDigest, pgDigest, err := v.digests(ctx, r[0], r[1])
+		if err != nil {
+			return err
+		}
+		if err := v.verify(ctx, r[0], r[1], mongoDigest, pgDigest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compareLeaf compara produto a produto um intervalo divergente
+func (v *merkleVerifier) compareLeaf(ctx context.Context, lo, hi int) error {
+	cursor, err := v.collection.Find(ctx, idRange(lo, hi), options.Find().
+		SetSort(bson.D{{Key: "product_id", Value: 1}}).
+		SetProjection(productProjection))
+	if err != nil {
+		return fmt.Errorf("erro ao buscar documentos no MongoDB: %w", err)
+	}
+	defer cursor.Close(context.WithoutCancel(ctx))
+
+	rows, err := v.pg.QueryProductRange(ctx, lo, hi)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar produtos no PostgreSQL: %w", err)
+	}
+	defer rows.Close()
+
+	return v.report.compare(ctx, cursor, rows)
+}
+
+// digests calcula o digest do intervalo nos dois bancos
+func (v *merkleVerifier) digests(ctx context.Context, lo, hi int) (rangeDigest, rangeDigest, error) {
+	mongoDigest, err := v.mongoDigest(ctx, lo, hi)
+	if err != nil {
+		return rangeDigest{}, rangeDigest{}, err
+	}
+	var pgDigest rangeDigest
+	err = v.pg.GetDB().QueryRowContext(ctx, rowDigestSQL, lo, hi).Scan(&pgDigest.Count, &pgDigest.Hi, &pgDigest.Lo)
+	if err != nil {
+		return rangeDigest{}, rangeDigest{}, fmt.Errorf("erro ao calcular o digest de %d..%d no PostgreSQL: %w", lo, hi, err)
+	}
+	return mongoDigest, pgDigest, nil
+}
+
+// mongoDigest calcula o digest do intervalo com uma aggregation
+func (v *merkleVerifier) mongoDigest(ctx context.Context, lo, hi int) (rangeDigest, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: idRange(lo, hi)}},
+		{{Key: "$project", Value: bson.M{"h": bson.M{"$function": bson.M{
+			"body": rowDigestJS,
+			"args": bson.A{"$product_id", "$name", "$description", "$price", "$created_at"},
+			"lang": "js",
+		}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   nil,
+			"count": bson.M{"$sum": 1},
+			"hi":    bson.M{"$sum": bson.M{"$toLong": bson.M{"$arrayElemAt": bson.A{"$h", 0}}}},
+			"lo":    bson.M{"$sum": bson.M{"$toLong": bson.M{"$arrayElemAt": bson.A{"$h", 1}}}},
+		}}},
+	}
+	cursor, err := v.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return rangeDigest{}, fmt.Errorf("erro ao calcular o digest de %d..%d no MongoDB: %w", lo, hi, err)
+	}
+	defer cursor.Close(context.WithoutCancel(ctx))
+
+	// Intervalo vazio: a aggregation não devolve nenhum grupo
+	var d rangeDigest
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&d); err != nil {
+			return rangeDigest{}, err
+		}
+	}
+	return d, cursor.Err()
+}
+
+// bounds retorna o menor e o maior ID considerando os dois bancos
+func (v *merkleVerifier) bounds(ctx context.Context) (lo, hi int, found bool, err error) {
+	var pgMin, pgMax sql.NullInt64
+	err = v.pg.GetDB().QueryRowContext(ctx, "SELECT MIN(id), MAX(id) FROM products").Scan(&pgMin, &pgMax)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("erro ao buscar os limites de id no PostgreSQL: %w", err)
+	}
+	if pgMin.Valid {
+		lo, hi, found = int(pgMin.Int64), int(pgMax.Int64), true
+	}
+
+	for _, order := range []int{1, -1} {
+		var doc struct {
+			ID int `bson:"product_id"`
+		}
+		opts := options.FindOne().
+			SetSort(bson.D{{Key: "product_id", Value: order}}).
+			SetProjection(bson.M{"product_id": 1})
+		err := v.collection.FindOne(ctx, bson.M{"product_id": bson.M{"$exists": true}}, opts).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return 0, 0, false, fmt.Errorf("erro ao buscar os limites de product_id no MongoDB: %w", err)
+		}
+		if !found {
+			lo, hi, found = doc.ID, doc.ID, true
+		}
+		lo, hi = min(lo, doc.ID), max(hi, doc.ID)
+	}
+	return lo, hi, found, nil
+}
+
+// idRange filtra os documentos com product_id entre lo e hi (inclusive)
+func idRange(lo, hi int) bson.M {
+	return bson.M{"product_id": bson.M{"$gte": lo, "$lte": hi}}
+}
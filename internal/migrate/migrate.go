@@ -0,0 +1,329 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"migration-go/internal/config"
+	"migration-go/internal/database"
+	"migration-go/internal/deadletter"
+	"migration-go/internal/transform"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Stats resume o resultado de uma execução de migração
+type Stats struct {
+	Strategy string
+	Read     int64
+	Written  int64
+	Failed   int64
+	Retries  int64
+	Duration time.Duration
+
+	// DeadLettered conta os documentos enviados para a dead-letter; Filtered,
+	// os descartados pelas transformações
+	DeadLettered int64
+	Filtered     int64
+	Batches      []BatchOutcome
+
+	// Checkpoint é o maior product_id com todos os anteriores confirmados
+	Checkpoint int
+
+	// Watermark é o maior created_at/updated_at lido no modo incremental
+	Watermark time.Time
+
+	// Partitions traz o progresso por partição nas estratégias particionadas
+	Partitions []PartitionStats
+}
+
+// Unsettled retorna os lotes com produtos que não foram gravados nem enviados
+// para a dead-letter
+func (s Stats) Unsettled() []BatchOutcome {
+	var out []BatchOutcome
+	for _, o := range s.Batches {
+		if !o.Settled() {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// RolledBack retorna os lotes cuja transação foi revertida
+func (s Stats) RolledBack() []BatchOutcome {
+	var out []BatchOutcome
+	for _, o := range s.Batches {
+		if !o.Committed {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// String formata o resumo da migração para exibição no terminal
+func (s Stats) String() string {
+	rolledBack := len(s.RolledBack())
+	return fmt.Sprintf("estratégia=%s lidos=%d gravados=%d falhas=%d dead-letter=%d filtrados=%d retentativas=%d lotes=%d (commit=%d rollback=%d) checkpoint=%d duração=%s",
+		s.Strategy, s.Read, s.Written, s.Failed, s.DeadLettered, s.Filtered, s.Retries,
+		len(s.Batches), len(s.Batches)-rolledBack, rolledBack, s.Checkpoint, s.Duration)
+}
+
+// Run conecta aos bancos, prepara o destino e executa a estratégia informada.
+// Com --dry-run, apenas o MongoDB é acessado. Os modos genéricos (jsonb e
+// mapping) leem em ordem de _id e não aceitam a estratégia partitioned.
+func Run(ctx context.Context, cfg *config.Config, strategy Strategy) (*Stats, error) {
+	switch cfg.App.Mode {
+	case "", ModeProducts:
+	case ModeJSONB:
+		target, err := jsonbTarget(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return runDocuments(ctx, cfg, strategy, target)
+	case ModeMapping:
+		return runMapping(ctx, cfg, strategy)
+	default:
+		return nil, fmt.Errorf("modo desconhecido %q (disponíveis: %s, %s, %s)", cfg.App.Mode, ModeProducts, ModeJSONB, ModeMapping)
+	}
+
+	if cfg.App.DryRun {
+		return dryRun(ctx, cfg, strategy)
+	}
+
+	// ---- 1. CONEXÕES ----
+	pgManager, mongoManager, disconnect, err := connect(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer disconnect()
+
+	return runEngine(ctx, cfg, strategy, pgManager, mongoManager)
+}
+
+// connect abre as conexões com o PostgreSQL e o MongoDB da configuração.
+// disconnect fecha as duas e deve ser chamado mesmo depois de um erro na leitura.
+func connect(ctx context.Context, cfg *config.Config) (*database.PostgresManager, *database.MongoManager, func(), error) {
+	pgManager := database.NewPostgresManager(&cfg.Postgres)
+	if err := pgManager.Connect(); err != nil {
+		return nil, nil, nil, err
+	}
+	fmt.Println("Conectado ao PostgreSQL!")
+
+	mongoManager := database.NewMongoManager(&cfg.MongoDB)
+	if err := mongoManager.Connect(ctx); err != nil {
+		pgManager.Close()
+		return nil, nil, nil, err
+	}
+	fmt.Println("Conectado ao MongoDB!")
+
+	disconnect := func() {
+		mongoManager.Disconnect(context.Background())
+		pgManager.Close()
+	}
+	return pgManager, mongoManager, disconnect, nil
+}
+
+// sourceKey identifica a coleção de origem ("banco.coleção") em checkpoints,
+// marcas d'água, resume tokens, dead-letter e relatórios
+func sourceKey(cfg *config.Config) string {
+	return cfg.MongoDB.Database + "." + cfg.MongoDB.Collection
+}
+
+// resumeHint orienta como continuar uma migração interrompida, conforme o modo
+func resumeHint(cfg *config.Config, stats *Stats) string {
+	switch {
+	case cfg.App.Incremental:
+		// A marca d'água não avança em uma sincronização interrompida
+		return "Rode de novo com --incremental para reler as alterações desde a última marca d'água."
+	case strings.TrimSpace(cfg.App.SourceSort) != "":
+		return "Com --sort o checkpoint não é salvo; refaça a migração desde o início."
+	default:
+		return fmt.Sprintf("Continue com --resume a partir do product_id %d.", stats.Checkpoint)
+	}
+}
+
+// newEngine cria o Engine do modo products com a consulta à origem e as
+// opções de transformação e escrita da configuração. O checkpoint começa do
+// zero e não é persistido.
+func newEngine(cfg *config.Config, collection *mongo.Collection, sink Sink, deadLetter deadletter.Sink) (*Engine, error) {
+	e, err := engineFor(cfg, collection, "product_id", deadLetter)
+	if err != nil {
+		return nil, err
+	}
+	e.sink = sink
+	e.products = productPipeline(e)
+	e.flow = e.products
+	return e, nil
+}
+
+// engineFor cria o Engine sem pipeline. key é o campo que ordena a leitura e
+// que a projeção da origem precisa manter.
+func engineFor(cfg *config.Config, collection *mongo.Collection, key string, deadLetter deadletter.Sink) (*Engine, error) {
+	chain, err := transform.Parse(cfg.App.Transforms)
+	if err != nil {
+		return nil, err
+	}
+	query, err := newSourceQuery(&cfg.App, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Engine{
+		source:     sourceKey(cfg),
+		collection: collection,
+		query:      query,
+		deadLetter: deadLetter,
+		checkpoint: newWatermark(nil, 0),
+		transform:  chain,
+		retry:      newRetryPolicy(cfg),
+		workers:    max(cfg.App.NumWorkers, 1),
+		batchSize:  max(cfg.App.BatchSize, 1),
+
+		partitionCount:  cfg.App.Partitions,
+		partitionMethod: cfg.App.PartitionMethod,
+	}, nil
+}
+
+// runEngine prepara o destino e executa a estratégia com conexões já abertas.
+// Leituras no MongoDB usam ctx, que pode carregar uma sessão (ex.: snapshot).
+func runEngine(ctx context.Context, cfg *config.Config, strategy Strategy, pgManager *database.PostgresManager, mongoManager *database.MongoManager) (*Stats, error) {
+	// ---- 2. PREPARAÇÃO DO DESTINO ----
+	sink, err := newSink(cfg.App.WriteMethod, cfg.App.OnConflict, pgManager.GetDB())
+	if err != nil {
+		return nil, err
+	}
+
+	source := sourceKey(cfg)
+	store := &checkpointStore{db: pgManager.GetDB(), key: source}
+	if err := store.setup(ctx); err != nil {
+		return nil, fmt.Errorf("erro ao criar a tabela de checkpoints: %w", err)
+	}
+
+	if cfg.App.Resume && strings.TrimSpace(cfg.App.SourceSort) != "" {
+		return nil, errors.New("--resume depende da leitura em ordem de product_id e não combina com --sort")
+	}
+	if cfg.App.Incremental && cfg.App.SourceLimit > 0 {
+		return nil, errors.New("o modo incremental precisa ler todas as alterações desde a marca d'água e não combina com --limit")
+	}
+
+	var resumeFrom *int
+	var syncState *syncStateStore
+	var seen *latestSeen
+	filter := bson.M{}
+	if cfg.App.Incremental {
+		// O modo incremental só acrescenta/atualiza linhas: nada de TRUNCATE,
+		// retomada por checkpoint ou INSERT simples. A política newer compara
+		// apenas created_at e descartaria as alterações que só mudam updated_at.
+		if cfg.App.Truncate || cfg.App.Resume || cfg.App.OnConflict == ConflictError || cfg.App.OnConflict == ConflictNewer {
+			return nil, errors.New("o modo incremental exige --on-conflict skip ou overwrite e não combina com --truncate ou --resume")
+		}
+
+		syncState = &syncStateStore{db: pgManager.GetDB(), key: source}
+		if err := syncState.setup(ctx); err != nil {
+			return nil, fmt.Errorf("erro ao criar a tabela de marcas d'água: %w", err)
+		}
+		since, found, err := syncState.load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			fmt.Printf("Sincronização incremental de %s a partir de %s\n", source, since.Format(time.RFC3339Nano))
+			filter = incrementalFilter(since)
+		} else {
+			fmt.Printf("Nenhuma marca d'água para %s; a primeira sincronização copia tudo.\n", source)
+		}
+		seen = newLatestSeen(since, time.Now())
+		store = nil
+	} else if cfg.App.Resume {
+		last, found, err := store.load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			fmt.Printf("Nenhum checkpoint encontrado para %s; retomando do início.\n", store.key)
+		} else {
+			fmt.Printf("Retomando %s a partir de product_id > %d\n", store.key, last)
+		}
+		resumeFrom = &last
+		filter = bson.M{"product_id": bson.M{"$gt": last}}
+	} else if err := store.reset(ctx); err != nil {
+		return nil, fmt.Errorf("erro ao limpar o checkpoint: %w", err)
+	}
+
+	if err := setupPostgresTarget(ctx, pgManager.GetDB(), cfg.App.Truncate, resumeFrom, cfg.App.OnConflict); err != nil {
+		return nil, fmt.Errorf("erro ao configurar o destino no PostgreSQL: %w", err)
+	}
+
+	deadLetter, err := deadletter.Open(ctx, cfg.App.DeadLetter, cfg.App.DeadLetterFile, pgManager.GetDB())
+	if err != nil {
+		return nil, err
+	}
+	if deadLetter != nil {
+		defer deadLetter.Close()
+	}
+
+	// ---- 3. MIGRAÇÃO ----
+	start := 0
+	if resumeFrom != nil {
+		start = *resumeFrom
+	}
+	engine, err := newEngine(cfg, mongoManager.GetCollection(), sink, deadLetter)
+	if err != nil {
+		return nil, err
+	}
+	engine.filter = filter
+	// Em outra ordem, o maior product_id confirmado não representa um prefixo
+	// contíguo da leitura; o checkpoint segue em memória, mas não é salvo
+	if len(engine.query.sort) > 0 {
+		store = nil
+	}
+	engine.checkpoint = newWatermark(store, start)
+	engine.checkpoints = store
+	engine.seen = seen
+
+	fmt.Printf("Iniciando a migração MongoDB -> PostgreSQL (estratégia %s, escrita %s, conflitos %s)...\n",
+		strategy.Name(), cfg.App.WriteMethod, cfg.App.OnConflict)
+	startTime := time.Now()
+	err = strategy.Migrate(ctx, engine)
+
+	stats := engine.stats()
+	stats.Strategy = strategy.Name()
+	stats.Duration = time.Since(startTime)
+	for _, p := range stats.Partitions {
+		fmt.Printf("  partição %d [%d..%d]: lidos=%d gravados=%d concluída=%t\n",
+			p.Index, p.Min, p.Max, p.Read, p.Written, p.Done)
+	}
+	if rolledBack := stats.RolledBack(); len(rolledBack) > 0 {
+		fmt.Printf("%d lotes revertidos; os intervalos abaixo NÃO foram gravados:\n", len(rolledBack))
+		for _, o := range rolledBack {
+			fmt.Printf("  %s\n", o)
+		}
+	}
+	if errors.Is(err, context.Canceled) {
+		fmt.Printf("Migração interrompida após %s: %d registros gravados. %s\n",
+			stats.Duration, stats.Written, resumeHint(cfg, stats))
+	}
+	if err != nil {
+		return stats, fmt.Errorf("migração %s interrompida: %w", strategy.Name(), err)
+	}
+
+	// A marca d'água só avança se todos os documentos lidos chegaram ao destino
+	// (ou à dead-letter); senão a próxima sincronização relê o mesmo intervalo
+	if syncState != nil {
+		if len(stats.Unsettled()) > 0 {
+			fmt.Println("Marca d'água mantida: há lotes não gravados nesta sincronização.")
+		} else if !stats.Watermark.IsZero() {
+			if err := syncState.save(context.WithoutCancel(ctx), stats.Watermark); err != nil {
+				return stats, err
+			}
+			fmt.Printf("Marca d'água de %s avançada para %s\n", source, stats.Watermark.Format(time.RFC3339Nano))
+		}
+	}
+
+	fmt.Printf("Migração concluída em %s!\n", stats.Duration)
+	return stats, nil
+}
@@ -0,0 +1,110 @@
+package migrate
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BatchOutcome registra o resultado da gravação de um lote
+type BatchOutcome struct {
+	Partition int
+	Seq       int64
+	Worker    int
+	Size      int
+	MinID     int
+	MaxID     int
+	Rows      int64
+	Attempts  int
+	Committed bool
+	Err       error
+	Duration  time.Duration
+
+	// Salvaged e DeadLettered contam, em um lote revertido, os produtos
+	// regravados individualmente e os enviados para a dead-letter
+	Salvaged     int
+	DeadLettered int
+}
+
+// Settled indica se todos os produtos do lote foram gravados ou guardados na
+// dead-letter, ou seja, se nenhum deles precisa ser relido na retomada
+func (o BatchOutcome) Settled() bool {
+	return o.Committed || o.Salvaged+o.DeadLettered == o.Size
+}
+
+// String descreve o lote e o intervalo de IDs que ele cobre
+func (o BatchOutcome) String() string {
+	status := "commit"
+	if !o.Committed {
+		kind := "erro permanente"
+		if isTransient(o.Err) {
+			kind = "tentativas esgotadas"
+		}
+		status = fmt.Sprintf("rollback, %s (%v)", kind, o.Err)
+		if o.Salvaged+o.DeadLettered > 0 {
+			status += fmt.Sprintf(" regravados=%d dead-letter=%d", o.Salvaged, o.DeadLettered)
+		}
+	}
+	ids := ""
+	if o.MinID != 0 || o.MaxID != 0 {
+		ids = fmt.Sprintf(" IDs %d..%d", o.MinID, o.MaxID)
+	}
+	return fmt.Sprintf("lote #%d.%d worker=%d%s produtos=%d linhas=%d tentativas=%d %s",
+		o.Partition, o.Seq, o.Worker, ids, o.Size, o.Rows, o.Attempts, status)
+}
+
+// newOutcome resume o resultado da gravação de batch. id retorna o product_id
+// de uma linha; sem ele (nos modos genéricos), MinID e MaxID ficam zerados.
+func newOutcome[T any](worker int, batch Batch[T], id func(T) int, rows int64, err error, duration time.Duration) BatchOutcome {
+	o := BatchOutcome{
+		Partition: batch.Partition,
+		Seq:       batch.Seq,
+		Worker:    worker,
+		Size:      len(batch.Rows),
+		Rows:      rows,
+		Committed: err == nil,
+		Err:       err,
+		Duration:  duration,
+	}
+	if id == nil {
+		return o
+	}
+	for i, row := range batch.Rows {
+		n := id(row)
+		if i == 0 || n < o.MinID {
+			o.MinID = n
+		}
+		if i == 0 || n > o.MaxID {
+			o.MaxID = n
+		}
+	}
+	return o
+}
+
+// outcomeLog acumula os resultados dos lotes gravados pelos workers
+type outcomeLog struct {
+	mu       sync.Mutex
+	outcomes []BatchOutcome
+}
+
+func (l *outcomeLog) add(o BatchOutcome) {
+	l.mu.Lock()
+	l.outcomes = append(l.outcomes, o)
+	l.mu.Unlock()
+}
+
+// snapshot retorna uma cópia dos resultados ordenada pela sequência dos lotes
+func (l *outcomeLog) snapshot() []BatchOutcome {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := append([]BatchOutcome(nil), l.outcomes...)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Partition != out[j].Partition {
+			return out[i].Partition < out[j].Partition
+		}
+		return out[i].Seq < out[j].Seq
+	})
+	return out
+}
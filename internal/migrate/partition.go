@@ -0,0 +1,266 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"migration-go/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Métodos de particionamento aceitos em AppConfig.PartitionMethod
+const (
+	PartitionMinMax     = "minmax"     // intervalos de mesma largura entre o menor e o maior product_id
+	PartitionBucketAuto = "bucketauto" // intervalos com quantidades parecidas de documentos ($bucketAuto)
+)
+
+// Partition é um intervalo fechado [Min, Max] de product_id lido por um cursor próprio
+type Partition struct {
+	Index int
+	Min   int
+	Max   int
+}
+
+// PartitionStats é o progresso de uma partição
+type PartitionStats struct {
+	Partition
+	Read    int64
+	Written int64
+	Done    bool
+}
+
+// partitionProgress acumula os contadores de cada partição
+type partitionProgress struct {
+	Partition
+	read    atomic.Int64
+	written atomic.Int64
+	done    atomic.Bool
+}
+
+// Partition divide a origem em até n intervalos de product_id e prepara o
+// checkpoint para acompanhar cada intervalo separadamente
+func (e *Engine) Partition(ctx context.Context, n int, method string) ([]Partition, error) {
+	var parts []Partition
+	var err error
+	switch method {
+	case "", PartitionMinMax:
+		parts, err = e.partitionMinMax(ctx, max(n, 1))
+	case PartitionBucketAuto:
+		parts, err = e.partitionBucketAuto(ctx, max(n, 1))
+	default:
+		return nil, fmt.Errorf("método de particionamento desconhecido %q (disponíveis: %s, %s)",
+			method, PartitionMinMax, PartitionBucketAuto)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	e.partitions = make([]*partitionProgress, len(parts))
+	for i, p := range parts {
+		e.partitions[i] = &partitionProgress{Partition: p}
+	}
+	e.checkpoint = newPartitionedWatermark(e.checkpoints, e.checkpoint.current(), parts)
+	return parts, nil
+}
+
+// partitionMinMax divide o intervalo entre o menor e o maior product_id em
+// n partes de mesma largura
+func (e *Engine) partitionMinMax(ctx context.Context, n int) ([]Partition, error) {
+	lo, found, err := e.boundary(ctx, 1)
+	if err != nil || !found {
+		return nil, err
+	}
+	hi, _, err := e.boundary(ctx, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	width := (hi - lo + n) / n
+	var parts []Partition
+	for start := lo; start <= hi; start += width {
+		parts = append(parts, Partition{Index: len(parts), Min: start, Max: min(start+width-1, hi)})
+	}
+	return parts, nil
+}
+
+// boundary retorna o menor (order=1) ou o maior (order=-1) product_id da origem
+func (e *Engine) boundary(ctx context.Context, order int) (int, bool, error) {
+	var doc struct {
+		ID int `bson:"product_id"`
+	}
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "product_id", Value: order}}).
+		SetProjection(bson.M{"product_id": 1})
+	err := e.collection.FindOne(ctx, e.sourceFilter(), opts).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("erro ao buscar os limites de product_id: %w", err)
+	}
+	return doc.ID, true, nil
+}
+
+// partitionBucketAuto usa $bucketAuto para criar n intervalos com quantidades
+// parecidas de documentos, melhor para IDs com distribuição irregular
+func (e *Engine) partitionBucketAuto(ctx context.Context, n int) ([]Partition, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: e.sourceFilter()}},
+		{{Key: "$bucketAuto", Value: bson.M{"groupBy": "$product_id", "buckets": n}}},
+	}
+	cursor, err := e.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular as partições com $bucketAuto: %w", err)
+	}
+
+	var buckets []struct {
+		ID struct {
+			Min int `bson:"min"`
+			Max int `bson:"max"`
+		} `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &buckets); err != nil {
+		return nil, fmt.Errorf("erro ao ler as partições do $bucketAuto: %w", err)
+	}
+
+	// O max de cada bucket é exclusivo, exceto no último
+	parts := make([]Partition, len(buckets))
+	for i, b := range buckets {
+		parts[i] = Partition{Index: i, Min: b.ID.Min, Max: b.ID.Max}
+		if i < len(buckets)-1 {
+			parts[i].Max = buckets[i+1].ID.Min - 1
+		}
+	}
+	return parts, nil
+}
+
+// StreamPartition percorre um cursor próprio restrito ao intervalo da partição
+func (e *Engine) StreamPartition(ctx context.Context, p Partition, fn func(models.Product) error) error {
+	filter := bson.M{"$and": bson.A{
+		e.sourceFilter(),
+		bson.M{"product_id": bson.M{"$gte": p.Min, "$lte": p.Max}},
+	}}
+	cursor, err := e.collection.Find(ctx, filter, e.query.options(bson.D{{Key: "product_id", Value: 1}}))
+	if err != nil {
+		return fmt.Errorf("erro ao buscar documentos da partição %d: %w", p.Index, err)
+	}
+	defer cursor.Close(context.WithoutCancel(ctx))
+
+	progress := e.partitions[p.Index]
+	for cursor.Next(ctx) {
+		for _, prod := range e.products.decode(ctx, cursor.Current) {
+			if n := progress.read.Add(1); n%progressEvery == 0 {
+				fmt.Printf("... partição %d [%d..%d]: %d registros lidos ...\n", p.Index, p.Min, p.Max, n)
+			}
+			if err := fn(prod); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	progress.done.Store(true)
+	fmt.Printf("Partição %d [%d..%d] lida: %d registros.\n", p.Index, p.Min, p.Max, progress.read.Load())
+	return nil
+}
+
+// partitionStats retorna um retrato do progresso de cada partição
+func (e *Engine) partitionStats() []PartitionStats {
+	out := make([]PartitionStats, len(e.partitions))
+	for i, p := range e.partitions {
+		out[i] = PartitionStats{
+			Partition: p.Partition,
+			Read:      p.read.Load(),
+			Written:   p.written.Load(),
+			Done:      p.done.Load(),
+		}
+	}
+	return out
+}
+
+// partitionedWatermark mantém um watermark por partição e calcula o
+// checkpoint global: todas as partições anteriores precisam estar completas
+// para que o checkpoint avance dentro da seguinte
+type partitionedWatermark struct {
+	mu    sync.Mutex
+	store *checkpointStore
+	start int
+	parts []*partitionMark
+	value int
+}
+
+// partitionMark acompanha o watermark de uma partição e se ela terminou
+type partitionMark struct {
+	Partition
+	mark    *watermark
+	batches int64
+	done    bool
+}
+
+func newPartitionedWatermark(store *checkpointStore, start int, parts []Partition) *partitionedWatermark {
+	pw := &partitionedWatermark{store: store, start: start, value: start}
+	for _, p := range parts {
+		pw.parts = append(pw.parts, &partitionMark{
+			Partition: p,
+			mark:      newWatermark(nil, max(start, p.Min-1)),
+		})
+	}
+	return pw
+}
+
+func (pw *partitionedWatermark) complete(ctx context.Context, o BatchOutcome) error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	if err := pw.parts[o.Partition].mark.complete(ctx, o); err != nil {
+		return err
+	}
+	return pw.advance(ctx)
+}
+
+func (pw *partitionedWatermark) finish(ctx context.Context, partition int, batches int64) error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	pw.parts[partition].done = true
+	pw.parts[partition].batches = batches
+	return pw.advance(ctx)
+}
+
+func (pw *partitionedWatermark) current() int {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	return pw.value
+}
+
+// advance recalcula o checkpoint global e o persiste se ele avançou. Uma
+// partição lida por completo e sem lotes pendentes conta até o seu Max, mesmo
+// que não existam documentos até esse ID.
+func (pw *partitionedWatermark) advance(ctx context.Context) error {
+	value := pw.start
+	for _, p := range pw.parts {
+		if p.done && p.mark.settled(p.batches) {
+			value = max(value, p.Max)
+			continue
+		}
+		value = max(value, p.mark.current())
+		break
+	}
+
+	if value <= pw.value {
+		return nil
+	}
+	pw.value = value
+	if pw.store == nil {
+		return nil
+	}
+	return pw.store.save(ctx, value)
+}
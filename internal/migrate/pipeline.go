@@ -0,0 +1,308 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"migration-go/internal/deadletter"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Feed produz as linhas a migrar, entregando cada uma para emit
+type Feed[T any] func(emit func(T) error) error
+
+// rowWriter grava lotes de linhas do tipo T. Cada lote é gravado em uma única
+// transação e Write retorna a quantidade de linhas afetadas.
+type rowWriter[T any] interface {
+	Write(ctx context.Context, batch []T) (int64, error)
+	Close() error
+}
+
+// flow é o pipeline do Engine visto pelas estratégias, que escolhem apenas
+// como a leitura chega à escrita, sem depender do tipo de linha. Com
+// concurrent, os lotes são distribuídos entre os workers.
+type flow interface {
+	migrateInMemory(ctx context.Context, concurrent bool) error
+	migrateStream(ctx context.Context, concurrent bool) error
+}
+
+// pipeline lê a origem, converte cada documento transformado em uma linha do
+// tipo T e grava as linhas em lotes, com retentativas, regravação linha a
+// linha e dead-letter. No modo products as linhas são models.Product; nos
+// modos jsonb e mapping, os valores de um tableTarget.
+type pipeline[T any] struct {
+	*Engine
+
+	// key é o campo que ordena a leitura (product_id ou _id)
+	key string
+	// convert converte um registro já transformado em linha
+	convert func(rec bson.Raw) (T, error)
+	// id retorna o product_id da linha para o checkpoint; nil se não houver
+	id func(T) int
+	// document é o conteúdo guardado na dead-letter quando a linha não é gravada
+	document func(T) interface{}
+	// newWriter abre o escritor de um worker
+	newWriter func(ctx context.Context) (rowWriter[T], error)
+}
+
+// find abre o cursor de origem ordenado por key, condição para que o
+// checkpoint represente um prefixo contíguo dos dados. Uma ordenação
+// configurada substitui a padrão e desativa o checkpoint persistido.
+func (p *pipeline[T]) find(ctx context.Context) (*mongo.Cursor, error) {
+	return p.collection.Find(ctx, p.sourceFilter(), p.query.options(bson.D{{Key: p.key, Value: 1}}))
+}
+
+// Stream percorre o cursor do MongoDB e entrega cada linha convertida para fn
+func (p *pipeline[T]) Stream(ctx context.Context, fn func(T) error) error {
+	cursor, err := p.find(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar documentos no MongoDB: %w", err)
+	}
+	defer cursor.Close(context.WithoutCancel(ctx))
+
+	for cursor.Next(ctx) {
+		for _, row := range p.decode(ctx, cursor.Current) {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+	}
+	return cursor.Err()
+}
+
+// ReadAll carrega todos os documentos da coleção para a memória de uma vez
+func (p *pipeline[T]) ReadAll(ctx context.Context) ([]T, error) {
+	cursor, err := p.find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar documentos no MongoDB: %w", err)
+	}
+	defer cursor.Close(context.WithoutCancel(ctx))
+
+	var rows []T
+	for cursor.Next(ctx) {
+		rows = append(rows, p.decode(ctx, cursor.Current)...)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao carregar todos os documentos para a memória: %w", err)
+	}
+	return rows, nil
+}
+
+// decode aplica as transformações ao documento e converte cada registro
+// resultante em linha. Documentos que falham na transformação ou na
+// conversão são enviados para a dead-letter e ignorados.
+func (p *pipeline[T]) decode(ctx context.Context, doc bson.Raw) []T {
+	records, err := p.transform.ApplyRaw(doc)
+	if err != nil {
+		log.Printf("Erro ao transformar documento do MongoDB: %v", err)
+		p.failed.Add(1)
+		p.reject(ctx, deadletter.StageTransform, doc, err)
+		return nil
+	}
+	if len(records) == 0 {
+		p.filtered.Add(1)
+		return nil
+	}
+
+	rows := make([]T, 0, len(records))
+	for _, rec := range records {
+		row, err := p.convert(rec)
+		if err != nil {
+			log.Printf("Erro ao decodificar documento do MongoDB: %v", err)
+			p.failed.Add(1)
+			p.reject(ctx, deadletter.StageDecode, rec, err)
+			continue
+		}
+		p.read.Add(1)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// write grava um lote com o escritor do worker e registra o resultado.
+// Falhas são contabilizadas e não interrompem a migração.
+func (p *pipeline[T]) write(ctx context.Context, worker int, w rowWriter[T], batch Batch[T]) {
+	if len(batch.Rows) == 0 {
+		return
+	}
+
+	start := time.Now()
+	rows, attempts, err := p.retry.do(ctx, func() (int64, error) {
+		return w.Write(ctx, batch.Rows)
+	})
+	p.retries.Add(int64(attempts - 1))
+	outcome := newOutcome(worker, batch, p.id, rows, err, time.Since(start))
+	outcome.Attempts = attempts
+	if err != nil {
+		log.Printf("Worker %d: %s", worker, outcome)
+		outcome.Salvaged, outcome.DeadLettered = p.salvage(ctx, w, batch.Rows, err)
+	}
+
+	p.outcomes.add(outcome)
+	if cpErr := p.checkpoint.complete(ctx, outcome); cpErr != nil {
+		log.Printf("Worker %d: %v", worker, cpErr)
+	}
+
+	n := int64(outcome.Size)
+	if err != nil {
+		n = int64(outcome.Salvaged)
+		p.failed.Add(int64(outcome.Size - outcome.Salvaged))
+	}
+	if p.partitions != nil {
+		p.partitions[batch.Partition].written.Add(n)
+	}
+	if total := p.written.Add(n); total/progressEvery != (total-n)/progressEvery {
+		fmt.Printf("... %d registros inseridos ...\n", total)
+	}
+}
+
+// salvage trata um lote revertido quando há dead-letter configurada. Em erros
+// permanentes, cada linha é regravada isoladamente para que só as inválidas
+// sejam rejeitadas; se as tentativas se esgotaram por erro transitório, o lote
+// inteiro vai para a dead-letter.
+func (p *pipeline[T]) salvage(ctx context.Context, w rowWriter[T], batch []T, cause error) (salvaged, rejected int) {
+	if p.deadLetter == nil {
+		return 0, 0
+	}
+
+	for _, row := range batch {
+		err := cause
+		if !isTransient(cause) {
+			_, _, err = p.retry.do(ctx, func() (int64, error) {
+				return w.Write(ctx, []T{row})
+			})
+		}
+
+		if err == nil {
+			salvaged++
+		} else if p.reject(ctx, deadletter.StageInsert, p.document(row), err) {
+			rejected++
+		}
+	}
+	return salvaged, rejected
+}
+
+// Sequential agrupa as linhas emitidas por feed em lotes de BatchSize e os
+// grava no mesmo goroutine, sem concorrência
+func (p *pipeline[T]) Sequential(ctx context.Context, feed Feed[T]) error {
+	w, err := p.newWriter(ctx)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	writeCtx := context.WithoutCancel(ctx)
+	b := newBatcher(p.batchSize, 0, func(batch Batch[T]) {
+		p.write(writeCtx, 0, w, batch)
+	})
+
+	// Mesmo se a leitura for interrompida, o lote parcial já lido é gravado
+	err = feed(b.add)
+	b.flush()
+	if err == nil {
+		err = p.checkpoint.finish(writeCtx, 0, b.seq)
+	}
+	return err
+}
+
+// FanOut agrupa as linhas emitidas pelos feeds em lotes de BatchSize e os
+// distribui entre os workers de escrita. Cada feed roda em seu próprio
+// goroutine e corresponde a uma partição de leitura, na mesma ordem.
+func (p *pipeline[T]) FanOut(ctx context.Context, feeds ...Feed[T]) error {
+	writers := make([]rowWriter[T], 0, p.workers)
+	defer func() {
+		for _, w := range writers {
+			w.Close()
+		}
+	}()
+	for i := 0; i < p.workers; i++ {
+		w, err := p.newWriter(ctx)
+		if err != nil {
+			return err
+		}
+		writers = append(writers, w)
+	}
+
+	// Os workers gravam com um contexto que não é cancelado pelo sinal de
+	// parada: o que já está no canal é drenado e confirmado antes de sair
+	writeCtx := context.WithoutCancel(ctx)
+	batchChan := make(chan Batch[T], p.workers)
+	var wg sync.WaitGroup
+
+	for i, w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batchChan {
+				p.write(writeCtx, i, w, batch)
+			}
+		}()
+	}
+
+	errs := make([]error, len(feeds))
+	var readers sync.WaitGroup
+	for i, feed := range feeds {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			b := newBatcher(p.batchSize, i, func(batch Batch[T]) {
+				batchChan <- batch
+			})
+
+			// Mesmo se a leitura for interrompida, o lote parcial já lido é gravado
+			errs[i] = feed(b.add)
+			b.flush()
+			if errs[i] == nil {
+				errs[i] = p.checkpoint.finish(writeCtx, i, b.seq)
+			}
+		}()
+	}
+
+	readers.Wait()
+	close(batchChan)
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// migrateInMemory carrega a origem inteira e só então grava os lotes
+func (p *pipeline[T]) migrateInMemory(ctx context.Context, concurrent bool) error {
+	rows, err := p.ReadAll(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d documentos carregados na memória.\n", len(rows))
+
+	if concurrent {
+		return p.FanOut(ctx, emitAll(rows))
+	}
+	return p.Sequential(ctx, emitAll(rows))
+}
+
+// migrateStream grava os lotes à medida que o cursor é lido
+func (p *pipeline[T]) migrateStream(ctx context.Context, concurrent bool) error {
+	feed := func(emit func(T) error) error {
+		return p.Stream(ctx, emit)
+	}
+	if concurrent {
+		return p.FanOut(ctx, feed)
+	}
+	return p.Sequential(ctx, feed)
+}
+
+// emitAll entrega ao pipeline as linhas já carregadas em memória
+func emitAll[T any](rows []T) Feed[T] {
+	return func(emit func(T) error) error {
+		for _, row := range rows {
+			if err := emit(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
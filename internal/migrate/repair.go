@@ -0,0 +1,169 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"migration-go/internal/config"
+	"migration-go/internal/models"
+
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RepairReport descreve o que o repair alterou no PostgreSQL
+type RepairReport struct {
+	Source string `json:"source"`
+
+	// Upserted são IDs regravados a partir da origem; Deleted, IDs removidos
+	// do destino por não existirem mais na origem; Failed, IDs cujo documento
+	// não pôde ser decodificado ou cujo lote não pôde ser gravado
+	Upserted []int `json:"upserted"`
+	Deleted  []int `json:"deleted"`
+	Failed   []int `json:"failed"`
+
+	Duration time.Duration `json:"-"`
+}
+
+// String formata o resumo do reparo para exibição no terminal
+func (r RepairReport) String() string {
+	return fmt.Sprintf("regravados=%d removidos=%d falhas=%d duração=%s",
+		len(r.Upserted), len(r.Deleted), len(r.Failed), r.Duration)
+}
+
+// WriteFile grava o relatório em JSON
+func (r RepairReport) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("erro ao gravar o relatório de reparo: %w", err)
+	}
+	return nil
+}
+
+// Repair corrige no PostgreSQL os IDs divergentes de um relatório do verify.
+// Cada ID ausente, extra ou divergente é relido do MongoDB: se o documento
+// existe, a linha é regravada com upsert; se não existe, a linha é removida.
+// Os IDs são processados em lotes de BatchSize, cada lote em uma transação.
+func Repair(ctx context.Context, cfg *config.Config, verify *VerifyReport) (*RepairReport, error) {
+	if err := wholeCollection(&cfg.App, "repair"); err != nil {
+		return nil, err
+	}
+	source := sourceKey(cfg)
+	if verify.Source != "" && verify.Source != source {
+		return nil, fmt.Errorf("o relatório é de %s, mas a origem configurada é %s", verify.Source, source)
+	}
+
+	pgManager, mongoManager, disconnect, err := connect(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer disconnect()
+
+	db := pgManager.GetDB()
+	if err := createProductsTable(ctx, db); err != nil {
+		return nil, fmt.Errorf("erro ao configurar o destino no PostgreSQL: %w", err)
+	}
+
+	ids := slices.Concat(verify.Missing, verify.Mismatched, verify.Extra)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	fmt.Printf("Reparando %d IDs divergentes de %s...\n", len(ids), source)
+
+	startTime := time.Now()
+	report := &RepairReport{Source: source}
+	retry := newRetryPolicy(cfg)
+	for chunk := range slices.Chunk(ids, max(cfg.App.BatchSize, 1)) {
+		if err := ctx.Err(); err != nil {
+			report.Duration = time.Since(startTime)
+			return report, err
+		}
+
+		products, failed, err := fetchProducts(ctx, mongoManager.GetCollection(), chunk)
+		if err != nil {
+			return report, err
+		}
+		report.Failed = append(report.Failed, failed...)
+
+		// IDs sem documento na origem (nem mesmo inválido) são removidos do destino
+		var deleted []int
+		for _, id := range chunk {
+			_, found := slices.BinarySearchFunc(products, id, func(p models.Product, id int) int { return p.ID - id })
+			if !found && !slices.Contains(failed, id) {
+				deleted = append(deleted, id)
+			}
+		}
+
+		_, _, err = retry.do(ctx, func() (int64, error) {
+			return 0, repairChunk(ctx, db, products, deleted)
+		})
+		if err != nil {
+			log.Printf("Erro ao reparar os IDs %d..%d: %v", chunk[0], chunk[len(chunk)-1], err)
+			for _, p := range products {
+				report.Failed = append(report.Failed, p.ID)
+			}
+			report.Failed = append(report.Failed, deleted...)
+			continue
+		}
+		for _, p := range products {
+			report.Upserted = append(report.Upserted, p.ID)
+		}
+		report.Deleted = append(report.Deleted, deleted...)
+	}
+
+	slices.Sort(report.Failed)
+	report.Duration = time.Since(startTime)
+	return report, nil
+}
+
+// fetchProducts relê da origem os documentos com os IDs informados, ordenados
+// por product_id. Documentos que não decodificam são devolvidos em failed.
+func fetchProducts(ctx context.Context, collection *mongo.Collection, ids []int) (products []models.Product, failed []int, err error) {
+	cursor, err := collection.Find(ctx, bson.M{"product_id": bson.M{"$in": ids}},
+		options.Find().SetSort(bson.D{{Key: "product_id", Value: 1}}))
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar documentos no MongoDB: %w", err)
+	}
+	defer cursor.Close(context.WithoutCancel(ctx))
+
+	for cursor.Next(ctx) {
+		var p models.Product
+		if err := cursor.Decode(&p); err != nil {
+			id, _ := cursor.Current.Lookup("product_id").AsInt64OK()
+			log.Printf("Documento product_id=%d inválido no MongoDB: %v", id, err)
+			failed = append(failed, int(id))
+			continue
+		}
+		products = append(products, p)
+	}
+	return products, failed, cursor.Err()
+}
+
+// repairChunk regrava e remove as linhas de um lote na mesma transação
+func repairChunk(ctx context.Context, db *sql.DB, products []models.Product, deleted []int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := insertProducts(ctx, tx, products, ConflictOverwrite); err != nil {
+		return err
+	}
+	if len(deleted) > 0 {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM products WHERE id = ANY($1)`, pq.Array(deleted)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,224 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"migration-go/internal/config"
+	"migration-go/internal/database"
+	"migration-go/internal/deadletter"
+	"migration-go/internal/models"
+	"migration-go/internal/transform"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ReplayStats resume o reprocessamento da dead-letter
+type ReplayStats struct {
+	Total    int
+	Replayed int
+	Failed   int
+	Skipped  int
+
+	// Filtered conta as entradas descartadas pelas transformações configuradas
+	Filtered int
+}
+
+// String formata o resumo do reprocessamento para exibição no terminal
+func (s ReplayStats) String() string {
+	return fmt.Sprintf("entradas=%d regravadas=%d falhas=%d ignoradas=%d filtradas=%d",
+		s.Total, s.Replayed, s.Failed, s.Skipped, s.Filtered)
+}
+
+// Replay regrava no PostgreSQL os documentos guardados na dead-letter
+// configurada. No modo arquivo, as entradas que falharem de novo são gravadas
+// em "<arquivo>.failed"; no modo tabela, as regravadas com sucesso são removidas.
+// Entradas guardadas antes das transformações passam de novo pela cadeia
+// configurada (ver replayRecords).
+func Replay(ctx context.Context, cfg *config.Config) (*ReplayStats, error) {
+	chain, err := transform.Parse(cfg.App.Transforms)
+	if err != nil {
+		return nil, err
+	}
+
+	pgManager := database.NewPostgresManager(&cfg.Postgres)
+	if err := pgManager.Connect(); err != nil {
+		return nil, err
+	}
+	defer pgManager.Close()
+	fmt.Println("Conectado ao PostgreSQL!")
+
+	db := pgManager.GetDB()
+	if err := createProductsTable(ctx, db); err != nil {
+		return nil, fmt.Errorf("erro ao configurar o destino no PostgreSQL: %w", err)
+	}
+
+	source := sourceKey(cfg)
+	if err := validateConflictPolicy(cfg.App.OnConflict); err != nil {
+		return nil, err
+	}
+	sink := insertSink{db: db, policy: cfg.App.OnConflict}
+	retry := newRetryPolicy(cfg)
+	stats := &ReplayStats{}
+
+	// replayEntry regrava os produtos de uma entrada em uma transação e retorna
+	// quantos foram regravados
+	replayEntry := func(entry deadletter.Entry) (int, error) {
+		records, err := replayRecords(entry, chain)
+		if err != nil {
+			return 0, err
+		}
+		products := make([]models.Product, len(records))
+		for i, rec := range records {
+			if err := bson.Unmarshal(rec, &products[i]); err != nil {
+				return 0, fmt.Errorf("erro ao decodificar documento da dead-letter: %w", err)
+			}
+		}
+		if len(products) == 0 {
+			return 0, nil
+		}
+		_, _, err = retry.do(ctx, func() (int64, error) {
+			return sink.Write(ctx, products)
+		})
+		return len(products), err
+	}
+
+	switch cfg.App.DeadLetter {
+	case deadletter.KindTable:
+		table, err := deadletter.OpenTable(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("Reprocessando migration_errors da origem %s...\n", source)
+
+		err = table.Scan(ctx, source, func(entry deadletter.Entry) error {
+			stats.Total++
+			n, err := replayEntry(entry)
+			if errors.Is(err, errNotReplayable) {
+				log.Printf("Entrada %d ignorada e mantida na tabela: %v", entry.ID, err)
+				stats.Skipped++
+				return nil
+			}
+			if err != nil {
+				log.Printf("Entrada %d continua falhando: %v", entry.ID, err)
+				stats.Failed++
+				return nil
+			}
+			if n == 0 {
+				log.Printf("Entrada %d descartada pelas transformações e removida da tabela", entry.ID)
+				stats.Filtered++
+			} else {
+				stats.Replayed++
+			}
+			return table.Delete(ctx, entry.ID)
+		})
+		if err != nil {
+			return stats, err
+		}
+
+	case deadletter.KindFile:
+		failedPath := cfg.App.DeadLetterFile + ".failed"
+		if err := os.Remove(failedPath); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		failed, err := deadletter.OpenFile(failedPath)
+		if err != nil {
+			return nil, err
+		}
+		defer failed.Close()
+		fmt.Printf("Reprocessando %s...\n", cfg.App.DeadLetterFile)
+
+		err = deadletter.ScanFile(cfg.App.DeadLetterFile, func(entry deadletter.Entry) error {
+			stats.Total++
+			if entry.Source != source {
+				stats.Skipped++
+				return nil
+			}
+			n, err := replayEntry(entry)
+			if errors.Is(err, errNotReplayable) {
+				log.Printf("Linha %d ignorada e copiada para %s: %v", entry.ID, failedPath, err)
+				stats.Skipped++
+				return failed.Write(ctx, entry)
+			}
+			if err != nil {
+				log.Printf("Linha %d continua falhando: %v", entry.ID, err)
+				stats.Failed++
+				entry.Error = err.Error()
+				return failed.Write(ctx, entry)
+			}
+			if n == 0 {
+				log.Printf("Linha %d descartada pelas transformações", entry.ID)
+				stats.Filtered++
+			} else {
+				stats.Replayed++
+			}
+			return nil
+		})
+		if err != nil {
+			return stats, err
+		}
+		if stats.Failed > 0 || stats.Skipped > 0 {
+			fmt.Printf("Entradas que ainda falham ou não podem ser regravadas foram gravadas em %s\n", failedPath)
+		}
+
+	default:
+		return nil, fmt.Errorf("dead-letter %q não pode ser reprocessada", cfg.App.DeadLetter)
+	}
+
+	return stats, nil
+}
+
+// replayRecords retorna os registros a regravar a partir de uma entrada.
+// Entradas das etapas decode e insert já foram transformadas; as das etapas
+// transform e change guardam o documento de antes das transformações, que por
+// isso passa de novo pela cadeia e pode virar zero ou vários registros.
+func replayRecords(entry deadletter.Entry, chain transform.Chain) ([]bson.Raw, error) {
+	doc, err := replayDocument(entry)
+	if err != nil {
+		return nil, err
+	}
+	switch entry.Stage {
+	case deadletter.StageTransform, deadletter.StageChange:
+		records, err := chain.ApplyRaw(doc)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao transformar documento da dead-letter: %w", err)
+		}
+		return records, nil
+	default:
+		return []bson.Raw{doc}, nil
+	}
+}
+
+// errNotReplayable indica uma entrada que o replay não sabe regravar
+var errNotReplayable = errors.New("entrada não pode ser regravada")
+
+// replayDocument retorna o documento a regravar. Entradas da etapa change
+// guardam o evento inteiro do change stream: só inserts, updates e replaces
+// com fullDocument podem ser regravados; deletes sem pre-image e eventos
+// inválidos são ignorados.
+func replayDocument(entry deadletter.Entry) (bson.Raw, error) {
+	var doc bson.Raw
+	if err := entry.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if entry.Stage != deadletter.StageChange {
+		return doc, nil
+	}
+
+	var ev changeEvent
+	if err := bson.Unmarshal(doc, &ev); err != nil {
+		return nil, fmt.Errorf("%w: evento inválido: %v", errNotReplayable, err)
+	}
+	switch ev.OperationType {
+	case "insert", "update", "replace":
+		if len(ev.FullDocument) > 0 {
+			return ev.FullDocument, nil
+		}
+		return nil, fmt.Errorf("%w: evento %s sem fullDocument", errNotReplayable, ev.OperationType)
+	default:
+		return nil, fmt.Errorf("%w: evento %s não é regravado pelo replay", errNotReplayable, ev.OperationType)
+	}
+}
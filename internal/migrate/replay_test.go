@@ -0,0 +1,104 @@
+package migrate
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"migration-go/internal/deadletter"
+	"migration-go/internal/transform"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestReplayDocument(t *testing.T) {
+	product := bson.M{"product_id": 7, "name": "Caneta"}
+	tests := []struct {
+		name    string
+		stage   string
+		doc     interface{}
+		wantID  int
+		wantErr error
+	}{
+		{"etapa decode regrava o documento", deadletter.StageDecode, product, 7, nil},
+		{"etapa insert regrava o documento", deadletter.StageInsert, product, 7, nil},
+		{"insert do change stream usa fullDocument", deadletter.StageChange,
+			bson.M{"operationType": "insert", "fullDocument": product}, 7, nil},
+		{"update do change stream usa fullDocument", deadletter.StageChange,
+			bson.M{"operationType": "update", "fullDocument": product}, 7, nil},
+		{"update sem fullDocument é ignorado", deadletter.StageChange,
+			bson.M{"operationType": "update"}, 0, errNotReplayable},
+		{"delete é ignorado", deadletter.StageChange,
+			bson.M{"operationType": "delete", "documentKey": bson.M{"_id": 1}}, 0, errNotReplayable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := deadletter.NewEntry("db.products", tt.stage, tt.doc, errors.New("falhou"))
+			doc, err := replayDocument(entry)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("erro = %v, quer %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var p struct {
+				ID int `bson:"product_id"`
+			}
+			if err := bson.Unmarshal(doc, &p); err != nil {
+				t.Fatal(err)
+			}
+			if p.ID != tt.wantID {
+				t.Errorf("product_id = %d, quer %d", p.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestReplayRecords(t *testing.T) {
+	chain, err := transform.Parse("rename:sku=product_id;filter:status=inativo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := bson.M{"sku": 7, "name": "Caneta"}
+	transformed := bson.M{"product_id": 7, "name": "Caneta"}
+
+	tests := []struct {
+		name    string
+		stage   string
+		doc     interface{}
+		wantIDs []int
+		wantErr bool
+	}{
+		{"etapa transform passa pela cadeia", deadletter.StageTransform, raw, []int{7}, false},
+		{"etapa change transforma o fullDocument", deadletter.StageChange,
+			bson.M{"operationType": "insert", "fullDocument": raw}, []int{7}, false},
+		{"etapa decode já está transformada", deadletter.StageDecode, transformed, []int{7}, false},
+		{"etapa insert já está transformada", deadletter.StageInsert, transformed, []int{7}, false},
+		{"documento descartado pelo filtro", deadletter.StageTransform,
+			bson.M{"sku": 8, "status": "inativo"}, nil, false},
+		{"delete do change stream não é regravado", deadletter.StageChange,
+			bson.M{"operationType": "delete"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := deadletter.NewEntry("db.products", tt.stage, tt.doc, errors.New("falhou"))
+			records, err := replayRecords(entry, chain)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("erro = %v, quer erro %t", err, tt.wantErr)
+			}
+			var ids []int
+			for _, rec := range records {
+				var p struct {
+					ID int `bson:"product_id"`
+				}
+				if err := bson.Unmarshal(rec, &p); err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, p.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("product_ids = %v, quer %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package migrate
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+
+	"migration-go/internal/config"
+
+	"github.com/lib/pq"
+)
+
+// maxRetryDelay limita o crescimento exponencial da espera entre tentativas
+const maxRetryDelay = 30 * time.Second
+
+// retryPolicy repete operações que falharam por erros transitórios do PostgreSQL
+type retryPolicy struct {
+	maxRetries int
+	baseDelay  time.Duration
+}
+
+// newRetryPolicy cria a política de retentativas da configuração
+func newRetryPolicy(cfg *config.Config) retryPolicy {
+	return retryPolicy{maxRetries: max(cfg.App.MaxRetries, 0), baseDelay: cfg.App.RetryBackoff}
+}
+
+// do executa fn até ter sucesso, encontrar um erro permanente ou esgotar as
+// tentativas. Retorna o resultado, a quantidade de tentativas e o último erro.
+func (r retryPolicy) do(ctx context.Context, fn func() (int64, error)) (int64, int, error) {
+	for attempt := 1; ; attempt++ {
+		rows, err := fn()
+		if err == nil || !isTransient(err) || attempt > r.maxRetries {
+			return rows, attempt, err
+		}
+
+		select {
+		case <-time.After(r.backoff(attempt)):
+		case <-ctx.Done():
+			return rows, attempt, err
+		}
+	}
+}
+
+// backoff calcula a espera antes da próxima tentativa com "full jitter":
+// um valor aleatório entre zero e baseDelay * 2^(attempt-1)
+func (r retryPolicy) backoff(attempt int) time.Duration {
+	ceiling := r.baseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > maxRetryDelay {
+		ceiling = maxRetryDelay
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// isTransient indica se vale a pena repetir a operação que falhou com err.
+// Falhas de serialização, deadlocks, excesso de conexões e quedas de conexão
+// são transitórias; violações de constraint e demais erros são permanentes.
+func isTransient(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "40001", // serialization_failure
+			"40P01", // deadlock_detected
+			"53300", // too_many_connections
+			"57P01", // admin_shutdown
+			"57P02", // crash_shutdown
+			"57P03": // cannot_connect_now
+			return true
+		}
+		// Classe 08: connection_exception e derivados
+		return pqErr.Code.Class() == "08"
+	}
+	return isConnError(err)
+}
+
+// isConnError indica se err representa uma conexão perdida ou recusada
+func isConnError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,148 @@
+package migrate
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"migration-go/internal/config"
+
+	"github.com/lib/pq"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization_failure", &pq.Error{Code: "40001"}, true},
+		{"deadlock_detected", &pq.Error{Code: "40P01"}, true},
+		{"too_many_connections", &pq.Error{Code: "53300"}, true},
+		{"admin_shutdown", &pq.Error{Code: "57P01"}, true},
+		{"crash_shutdown", &pq.Error{Code: "57P02"}, true},
+		{"cannot_connect_now", &pq.Error{Code: "57P03"}, true},
+		{"connection_failure (classe 08)", &pq.Error{Code: "08006"}, true},
+		{"protocol_violation (classe 08)", &pq.Error{Code: "08P01"}, true},
+		{"envolvido com %w", fmt.Errorf("lote 3: %w", &pq.Error{Code: "40001"}), true},
+		{"unique_violation", &pq.Error{Code: "23505"}, false},
+		{"check_violation", &pq.Error{Code: "23514"}, false},
+		{"numeric_value_out_of_range", &pq.Error{Code: "22003"}, false},
+		{"string_data_right_truncation", &pq.Error{Code: "22001"}, false},
+		{"query_canceled", &pq.Error{Code: "57014"}, false},
+		{"driver.ErrBadConn", driver.ErrBadConn, true},
+		{"io.EOF", io.EOF, true},
+		{"io.ErrUnexpectedEOF", fmt.Errorf("leitura: %w", io.ErrUnexpectedEOF), true},
+		{"ECONNRESET", syscall.ECONNRESET, true},
+		{"ECONNREFUSED", syscall.ECONNREFUSED, true},
+		{"EPIPE", syscall.EPIPE, true},
+		{"net.Error", &net.OpError{Op: "dial", Err: errors.New("timeout")}, true},
+		{"erro genérico", errors.New("falhou"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransient(tt.err); got != tt.want {
+				t.Errorf("isTransient(%v) = %t, quer %t", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	transient := &pq.Error{Code: "40P01"}
+	permanent := &pq.Error{Code: "23505"}
+
+	tests := []struct {
+		name         string
+		maxRetries   int
+		errs         []error
+		wantAttempts int
+		wantErr      error
+	}{
+		{"sucesso na primeira", 3, []error{nil}, 1, nil},
+		{"transitório e depois sucesso", 3, []error{transient, transient, nil}, 3, nil},
+		{"permanente não é repetido", 3, []error{permanent, nil}, 1, permanent},
+		{"tentativas esgotadas", 2, []error{transient, transient, transient, nil}, 3, transient},
+		{"sem retentativas", 0, []error{transient, nil}, 1, transient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := retryPolicy{maxRetries: tt.maxRetries, baseDelay: time.Microsecond}
+			calls := 0
+			_, attempts, err := r.do(context.Background(), func() (int64, error) {
+				err := tt.errs[calls]
+				calls++
+				return 1, err
+			})
+			if attempts != tt.wantAttempts || calls != tt.wantAttempts {
+				t.Errorf("tentativas = %d (chamadas %d), quer %d", attempts, calls, tt.wantAttempts)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("erro = %v, quer %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	r := retryPolicy{baseDelay: 100 * time.Millisecond}
+	for attempt := 1; attempt <= 40; attempt++ {
+		ceiling := min(100*time.Millisecond<<(attempt-1), maxRetryDelay)
+		if attempt > 20 {
+			ceiling = maxRetryDelay
+		}
+		if d := r.backoff(attempt); d < 0 || d > ceiling {
+			t.Errorf("backoff(%d) = %s, fora de [0, %s]", attempt, d, ceiling)
+		}
+	}
+}
+
+// TestConfigHelpers cobre os auxiliares que todos os comandos usam para ler a
+// configuração antes de acessar os bancos
+func TestConfigHelpers(t *testing.T) {
+	tests := []struct {
+		name       string
+		mongo      config.MongoConfig
+		app        config.AppConfig
+		wantSource string
+		wantPolicy retryPolicy
+	}{
+		{
+			name:       "padrão",
+			mongo:      config.MongoConfig{Database: "loja", Collection: "products"},
+			app:        config.AppConfig{MaxRetries: 5, RetryBackoff: 200 * time.Millisecond},
+			wantSource: "loja.products",
+			wantPolicy: retryPolicy{maxRetries: 5, baseDelay: 200 * time.Millisecond},
+		},
+		{
+			name:       "sem retentativas",
+			mongo:      config.MongoConfig{Database: "db", Collection: "orders"},
+			wantSource: "db.orders",
+			wantPolicy: retryPolicy{},
+		},
+		{
+			name:       "retentativas negativas viram zero",
+			mongo:      config.MongoConfig{Database: "db", Collection: "orders"},
+			app:        config.AppConfig{MaxRetries: -3, RetryBackoff: time.Second},
+			wantSource: "db.orders",
+			wantPolicy: retryPolicy{maxRetries: 0, baseDelay: time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MongoDB: tt.mongo, App: tt.app}
+			if got := sourceKey(cfg); got != tt.wantSource {
+				t.Errorf("sourceKey = %q, quer %q", got, tt.wantSource)
+			}
+			if got := newRetryPolicy(cfg); got != tt.wantPolicy {
+				t.Errorf("newRetryPolicy = %+v, quer %+v", got, tt.wantPolicy)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"migration-go/internal/config"
+	"migration-go/internal/models"
+)
+
+// ReverseStats resume uma migração PostgreSQL -> MongoDB
+type ReverseStats struct {
+	Read     int64
+	Written  int64
+	Failed   int64
+	Batches  int64
+	Duration time.Duration
+}
+
+// String formata o resumo da migração reversa para exibição no terminal
+func (s ReverseStats) String() string {
+	return fmt.Sprintf("lidos=%d gravados=%d falhas=%d lotes=%d duração=%s",
+		s.Read, s.Written, s.Failed, s.Batches, s.Duration)
+}
+
+// ReverseOptions são as opções da migração reversa que destroem dados na
+// origem e, por isso, só valem quando pedidas na linha de comando. TRUNCATE
+// não se aplica aqui: ele se refere à tabela de destino da migração direta.
+type ReverseOptions struct {
+	// DropCollection remove a coleção do MongoDB antes da cópia
+	DropCollection bool
+}
+
+// Reverse copia a tabela products do PostgreSQL de volta para a coleção de
+// origem no MongoDB, base do plano de rollback do cutover. Os lotes são
+// gravados por NumWorkers workers: com DropCollection a coleção é recriada,
+// com índice em product_id, e recebe InsertMany; sem ele, cada lote é um
+// BulkWrite de upserts por product_id que só atualiza os campos do produto, e
+// a coleção e seus índices não são alterados.
+func Reverse(ctx context.Context, cfg *config.Config, ropts ReverseOptions) (*ReverseStats, error) {
+	pgManager, mongoManager, disconnect, err := connect(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer disconnect()
+
+	if ropts.DropCollection {
+		fmt.Printf("Removendo a coleção de destino %s no MongoDB...\n", cfg.MongoDB.Collection)
+		if err := mongoManager.DropCollection(ctx); err != nil {
+			return nil, fmt.Errorf("erro ao remover a coleção de destino: %w", err)
+		}
+		// A coleção recriada fica pronta para uma nova migração MongoDB -> PostgreSQL
+		if err := mongoManager.CreateIndex(ctx, "product_id"); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := pgManager.QueryProducts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar produtos no PostgreSQL: %w", err)
+	}
+	defer rows.Close()
+
+	write := func(ctx context.Context, products []models.Product) (int64, error) {
+		return mongoManager.UpsertMany(ctx, products)
+	}
+	if ropts.DropCollection {
+		write = func(ctx context.Context, products []models.Product) (int64, error) {
+			docs := make([]interface{}, len(products))
+			for i, p := range products {
+				docs[i] = p
+			}
+			if err := mongoManager.InsertMany(ctx, docs); err != nil {
+				return 0, err
+			}
+			return int64(len(products)), nil
+		}
+	}
+
+	fmt.Printf("Iniciando a migração PostgreSQL -> MongoDB (%s)...\n", cfg.MongoDB.Collection)
+	startTime := time.Now()
+	var read, written, failed, batches atomic.Int64
+
+	// Assim como na migração direta, os lotes já lidos são gravados mesmo
+	// depois de um sinal de parada
+	writeCtx := context.WithoutCancel(ctx)
+	workers := max(cfg.App.NumWorkers, 1)
+	batchChan := make(chan Batch[models.Product], workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batchChan {
+				n, err := write(writeCtx, batch.Rows)
+				batches.Add(1)
+				if err != nil {
+					log.Printf("Worker %d: lote #%d IDs %d..%d não gravado: %v", i, batch.Seq,
+						batch.Rows[0].ID, batch.Rows[len(batch.Rows)-1].ID, err)
+					failed.Add(int64(len(batch.Rows)))
+					continue
+				}
+				if total := written.Add(n); total/progressEvery != (total-n)/progressEvery {
+					fmt.Printf("... %d registros gravados no MongoDB ...\n", total)
+				}
+			}
+		}()
+	}
+
+	b := newBatcher(max(cfg.App.BatchSize, 1), 0, func(batch Batch[models.Product]) {
+		batchChan <- batch
+	})
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			log.Printf("Erro ao ler linha do PostgreSQL: %v", err)
+			failed.Add(1)
+			continue
+		}
+		read.Add(1)
+		b.add(p)
+	}
+	err = rows.Err()
+	b.flush()
+	close(batchChan)
+	wg.Wait()
+
+	stats := &ReverseStats{
+		Read:     read.Load(),
+		Written:  written.Load(),
+		Failed:   failed.Load(),
+		Batches:  batches.Load(),
+		Duration: time.Since(startTime),
+	}
+	if errors.Is(err, context.Canceled) {
+		fmt.Printf("Migração reversa interrompida após %s: %d registros gravados.\n", stats.Duration, stats.Written)
+	}
+	if err != nil {
+		return stats, fmt.Errorf("erro ao percorrer os produtos do PostgreSQL: %w", err)
+	}
+
+	fmt.Printf("Migração reversa concluída em %s!\n", stats.Duration)
+	return stats, nil
+}
+
+// scanProduct converte a linha atual de QueryProducts. description e
+// created_at aceitam NULL, que viram os valores zero do modelo.
+func scanProduct(rows *sql.Rows) (models.Product, error) {
+	var p models.Product
+	var description sql.NullString
+	var createdAt sql.NullTime
+	if err := rows.Scan(&p.ID, &p.Name, &description, &p.Price, &createdAt); err != nil {
+		return p, err
+	}
+	p.Description = description.String
+	p.CreatedAt = createdAt.Time
+	return p, nil
+}
@@ -0,0 +1,77 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"migration-go/internal/models"
+)
+
+// Métodos de escrita aceitos em AppConfig.WriteMethod
+const (
+	WriteInsert = "insert"
+	WriteCopy   = "copy"
+)
+
+// Sink representa o destino da migração. Cada worker de escrita abre o seu
+// próprio Writer, de modo que recursos de conexão não são compartilhados.
+type Sink interface {
+	NewWriter(ctx context.Context) (Writer, error)
+}
+
+// Writer grava lotes de produtos no destino. Cada lote é gravado em uma única
+// transação: ou todas as linhas são confirmadas, ou nenhuma. Write retorna a
+// quantidade de linhas afetadas.
+type Writer interface {
+	Write(ctx context.Context, batch []models.Product) (int64, error)
+	Close() error
+}
+
+// newSink cria o destino correspondente ao método de escrita e à política de
+// conflito configurados
+func newSink(method, policy string, db *sql.DB) (Sink, error) {
+	if err := validateConflictPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	switch method {
+	case "", WriteInsert:
+		return insertSink{db: db, policy: policy}, nil
+	case WriteCopy:
+		return copySink{db: db, policy: policy}, nil
+	default:
+		return nil, fmt.Errorf("método de escrita desconhecido %q (disponíveis: %s, %s)", method, WriteInsert, WriteCopy)
+	}
+}
+
+// insertSink grava com INSERTs de múltiplas linhas, um lote por transação
+type insertSink struct {
+	db     *sql.DB
+	policy string
+}
+
+func (s insertSink) NewWriter(ctx context.Context) (Writer, error) {
+	return s, nil
+}
+
+func (s insertSink) Write(ctx context.Context, batch []models.Product) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := insertProducts(ctx, tx, batch, s.policy)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return rows, nil
+}
+
+func (s insertSink) Close() error {
+	return nil
+}
@@ -0,0 +1,144 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"migration-go/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// sourceQuery é a consulta configurada para a coleção de origem: filtro,
+// projeção, ordenação e limite aplicados ao Find
+type sourceQuery struct {
+	filter     bson.M
+	projection bson.D
+	sort       bson.D
+	limit      int64
+}
+
+// newSourceQuery interpreta as opções SOURCE_* da configuração. key é o campo
+// de que o destino depende (product_id ou _id): projeções por inclusão sempre o
+// trazem e projeções que o excluem são recusadas.
+func newSourceQuery(app *config.AppConfig, key string) (sourceQuery, error) {
+	var q sourceQuery
+	if s := strings.TrimSpace(app.SourceFilter); s != "" {
+		if err := bson.UnmarshalExtJSON([]byte(s), false, &q.filter); err != nil {
+			return q, fmt.Errorf("filtro da origem inválido (esperado um documento em Extended JSON): %w", err)
+		}
+	}
+
+	var err error
+	if q.projection, err = parseFieldList(app.SourceProjection, 1, 0); err != nil {
+		return q, fmt.Errorf("projeção da origem inválida: %w", err)
+	}
+	if len(q.projection) > 0 {
+		inclusion, excludesKey := false, false
+		for _, e := range q.projection {
+			if e.Value == 1 && e.Key != "_id" {
+				inclusion = true
+			}
+			if e.Value == 0 && e.Key == key {
+				excludesKey = true
+			}
+		}
+		if excludesKey {
+			return q, fmt.Errorf("a projeção da origem não pode excluir %s", key)
+		}
+		if inclusion && !hasField(q.projection, key) {
+			q.projection = append(q.projection, bson.E{Key: key, Value: 1})
+		}
+	}
+
+	if q.sort, err = parseFieldList(app.SourceSort, 1, -1); err != nil {
+		return q, fmt.Errorf("ordenação da origem inválida: %w", err)
+	}
+
+	if app.SourceLimit < 0 {
+		return q, errors.New("o limite da origem não pode ser negativo")
+	}
+	q.limit = int64(app.SourceLimit)
+	return q, nil
+}
+
+// parseFieldList converte "campo1,-campo2" em um documento {campo1: asc, campo2: desc}
+func parseFieldList(s string, asc, desc int) (bson.D, error) {
+	var out bson.D
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		value := asc
+		if name, ok := strings.CutPrefix(field, "-"); ok {
+			field, value = name, desc
+		}
+		if field == "" || hasField(out, field) {
+			return nil, fmt.Errorf("campo vazio ou repetido em %q", s)
+		}
+		out = append(out, bson.E{Key: field, Value: value})
+	}
+	return out, nil
+}
+
+// hasField informa se o documento tem a chave de primeiro nível informada
+func hasField(d bson.D, key string) bool {
+	for _, e := range d {
+		if e.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// wholeCollection recusa a consulta configurada nos comandos que consideram a
+// coleção inteira: o change stream do sync e do cutover não passa pelo filtro,
+// e o verify e o repair comparam com a tabela products inteira
+func wholeCollection(app *config.AppConfig, command string) error {
+	q, err := newSourceQuery(app, "product_id")
+	if err != nil {
+		return err
+	}
+	if !q.isZero() {
+		return fmt.Errorf("o %s considera a coleção inteira e não combina com --filter, --projection, --sort ou --limit", command)
+	}
+	return nil
+}
+
+// isZero informa se nenhuma opção da consulta foi configurada
+func (q sourceQuery) isZero() bool {
+	return len(q.filter) == 0 && len(q.projection) == 0 && len(q.sort) == 0 && q.limit == 0
+}
+
+// where combina o filtro configurado com o filtro interno da leitura (retomada,
+// modo incremental ou intervalo de partição)
+func (q sourceQuery) where(base bson.M) bson.M {
+	switch {
+	case len(q.filter) == 0 && base == nil:
+		return bson.M{}
+	case len(q.filter) == 0:
+		return base
+	case len(base) == 0:
+		return q.filter
+	}
+	return bson.M{"$and": bson.A{base, q.filter}}
+}
+
+// options monta as opções do Find. A ordenação configurada substitui
+// defaultSort; o limite 0 lê todos os documentos.
+func (q sourceQuery) options(defaultSort bson.D) *options.FindOptions {
+	opts := options.Find().SetSort(defaultSort)
+	if len(q.sort) > 0 {
+		opts.SetSort(q.sort)
+	}
+	if len(q.projection) > 0 {
+		opts.SetProjection(q.projection)
+	}
+	if q.limit > 0 {
+		opts.SetLimit(q.limit)
+	}
+	return opts
+}
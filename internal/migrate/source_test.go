@@ -0,0 +1,130 @@
+package migrate
+
+import (
+	"reflect"
+	"testing"
+
+	"migration-go/internal/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseFieldList(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    bson.D
+		wantErr bool
+	}{
+		{"", nil, false},
+		{" , ", nil, false},
+		{"name", bson.D{{Key: "name", Value: 1}}, false},
+		{"name, -created_at", bson.D{{Key: "name", Value: 1}, {Key: "created_at", Value: -1}}, false},
+		{"a.b,-c.d", bson.D{{Key: "a.b", Value: 1}, {Key: "c.d", Value: -1}}, false},
+		{"name,name", nil, true},
+		{"name,-name", nil, true},
+		{"-", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseFieldList(tt.in, 1, -1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("erro = %v, quer erro %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFieldList(%q) = %v, quer %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSourceQuery(t *testing.T) {
+	tests := []struct {
+		name           string
+		app            config.AppConfig
+		key            string
+		wantProjection bson.D
+		wantErr        bool
+	}{
+		{name: "sem opções", key: "product_id"},
+		{name: "filtro", app: config.AppConfig{SourceFilter: `{"tenant": "acme"}`}, key: "product_id"},
+		{name: "filtro inválido", app: config.AppConfig{SourceFilter: `{tenant`}, key: "product_id", wantErr: true},
+		{name: "filtro que não é documento", app: config.AppConfig{SourceFilter: `[1]`}, key: "product_id", wantErr: true},
+		{
+			name: "inclusão traz a chave", app: config.AppConfig{SourceProjection: "name,price"}, key: "product_id",
+			wantProjection: bson.D{{Key: "name", Value: 1}, {Key: "price", Value: 1}, {Key: "product_id", Value: 1}},
+		},
+		{
+			name: "inclusão que já tem a chave", app: config.AppConfig{SourceProjection: "_id,name"}, key: "_id",
+			wantProjection: bson.D{{Key: "_id", Value: 1}, {Key: "name", Value: 1}},
+		},
+		{
+			name: "exclusão não acrescenta a chave", app: config.AppConfig{SourceProjection: "-description"}, key: "product_id",
+			wantProjection: bson.D{{Key: "description", Value: 0}},
+		},
+		{
+			name: "exclusão só do _id não é inclusão", app: config.AppConfig{SourceProjection: "-_id"}, key: "product_id",
+			wantProjection: bson.D{{Key: "_id", Value: 0}},
+		},
+		{name: "exclusão da chave", app: config.AppConfig{SourceProjection: "-product_id"}, key: "product_id", wantErr: true},
+		{name: "projeção repetida", app: config.AppConfig{SourceProjection: "name,name"}, key: "product_id", wantErr: true},
+		{name: "ordenação repetida", app: config.AppConfig{SourceSort: "name,-name"}, key: "product_id", wantErr: true},
+		{name: "limite negativo", app: config.AppConfig{SourceLimit: -1}, key: "product_id", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := newSourceQuery(&tt.app, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("erro = %v, quer erro %t", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(q.projection, tt.wantProjection) {
+				t.Errorf("projeção = %v, quer %v", q.projection, tt.wantProjection)
+			}
+		})
+	}
+}
+
+func TestWholeCollection(t *testing.T) {
+	tests := []struct {
+		name    string
+		app     config.AppConfig
+		wantErr bool
+	}{
+		{"sem opções", config.AppConfig{}, false},
+		{"filtro", config.AppConfig{SourceFilter: `{"tenant": "acme"}`}, true},
+		{"projeção", config.AppConfig{SourceProjection: "name"}, true},
+		{"ordenação", config.AppConfig{SourceSort: "-name"}, true},
+		{"limite", config.AppConfig{SourceLimit: 10}, true},
+		{"filtro inválido", config.AppConfig{SourceFilter: `{`}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := wholeCollection(&tt.app, "verify"); (err != nil) != tt.wantErr {
+				t.Errorf("erro = %v, quer erro %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSourceQueryWhere(t *testing.T) {
+	filter := bson.M{"tenant": "acme"}
+	base := bson.M{"product_id": bson.M{"$gt": 10}}
+	tests := []struct {
+		name  string
+		query sourceQuery
+		base  bson.M
+		want  bson.M
+	}{
+		{"nada", sourceQuery{}, nil, bson.M{}},
+		{"só a base", sourceQuery{}, base, base},
+		{"só o filtro", sourceQuery{filter: filter}, nil, filter},
+		{"base vazia", sourceQuery{filter: filter}, bson.M{}, filter},
+		{"ambos", sourceQuery{filter: filter}, base, bson.M{"$and": bson.A{base, filter}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.where(tt.base); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("where = %v, quer %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"migration-go/internal/models"
+)
+
+// Strategy define como os documentos lidos do MongoDB chegam ao PostgreSQL.
+// Conexões, preparação do destino e contadores ficam a cargo do Engine; a
+// estratégia decide apenas o fluxo entre leitura e escrita.
+type Strategy interface {
+	Name() string
+	Migrate(ctx context.Context, e *Engine) error
+}
+
+// Estratégias disponíveis, equivalentes aos antigos executáveis migrate_*
+var (
+	InMemory        Strategy = inMemory{}
+	InMemoryWorkers Strategy = inMemoryWorkers{}
+	Stream          Strategy = stream{}
+	StreamWorkers   Strategy = streamWorkers{}
+	Partitioned     Strategy = partitioned{}
+)
+
+// concurrentReader é implementada pelas estratégias que leem a origem com
+// vários cursores em paralelo
+type concurrentReader interface {
+	concurrentReads() bool
+}
+
+// productKeyed é implementada pelas estratégias que dependem de product_id e,
+// por isso, não servem aos modos genéricos (jsonb e mapping)
+type productKeyed interface {
+	keyedByProductID() bool
+}
+
+var strategies = map[string]Strategy{}
+
+func init() {
+	for _, s := range []Strategy{InMemory, InMemoryWorkers, Stream, StreamWorkers, Partitioned} {
+		Register(s)
+	}
+}
+
+// Register adiciona uma estratégia ao registro, indexada pelo nome
+func Register(s Strategy) {
+	strategies[s.Name()] = s
+}
+
+// Lookup retorna a estratégia registrada com o nome informado
+func Lookup(name string) (Strategy, error) {
+	s, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("estratégia desconhecida %q (disponíveis: %s)", name, strings.Join(Strategies(), ", "))
+	}
+	return s, nil
+}
+
+// Strategies retorna os nomes das estratégias registradas em ordem alfabética
+func Strategies() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// inMemory carrega tudo na memória e insere sequencialmente
+type inMemory struct{}
+
+func (inMemory) Name() string { return "simple" }
+
+func (inMemory) Migrate(ctx context.Context, e *Engine) error {
+	return e.flow.migrateInMemory(ctx, false)
+}
+
+// inMemoryWorkers carrega tudo na memória e insere com goroutines
+type inMemoryWorkers struct{}
+
+func (inMemoryWorkers) Name() string { return "goroutines" }
+
+func (inMemoryWorkers) Migrate(ctx context.Context, e *Engine) error {
+	return e.flow.migrateInMemory(ctx, true)
+}
+
+// stream lê via cursor e insere no mesmo loop, sem concorrência
+type stream struct{}
+
+func (stream) Name() string { return "stream" }
+
+func (stream) Migrate(ctx context.Context, e *Engine) error {
+	return e.flow.migrateStream(ctx, false)
+}
+
+// streamWorkers lê via cursor e distribui as inserções entre os workers
+type streamWorkers struct{}
+
+func (streamWorkers) Name() string { return "stream-goroutines" }
+
+func (streamWorkers) Migrate(ctx context.Context, e *Engine) error {
+	return e.flow.migrateStream(ctx, true)
+}
+
+// partitioned divide a origem em intervalos de product_id, cada um lido por
+// um cursor próprio, e distribui as inserções entre os workers
+type partitioned struct{}
+
+func (partitioned) Name() string { return "partitioned" }
+
+func (partitioned) concurrentReads() bool { return true }
+
+func (partitioned) keyedByProductID() bool { return true }
+
+func (partitioned) Migrate(ctx context.Context, e *Engine) error {
+	if e.products == nil {
+		return errors.New("a estratégia partitioned divide a origem por product_id e só vale no modo products")
+	}
+	if len(e.query.sort) > 0 || e.query.limit > 0 {
+		return errors.New("a estratégia partitioned lê cada intervalo em ordem de product_id e não combina com --sort ou --limit")
+	}
+
+	parts, err := e.Partition(ctx, e.partitionCount, e.partitionMethod)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Origem dividida em %d partições de product_id.\n", len(parts))
+
+	feeds := make([]Feed[models.Product], len(parts))
+	for i, p := range parts {
+		feeds[i] = func(emit func(models.Product) error) error {
+			return e.StreamPartition(ctx, p, emit)
+		}
+	}
+	return e.products.FanOut(ctx, feeds...)
+}
@@ -0,0 +1,157 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"migration-go/internal/models"
+)
+
+// productColumns são as colunas gravadas na tabela de destino, na ordem dos valores
+var productColumns = []string{"id", "name", "description", "price", "created_at"}
+
+// maxStatementParams é o limite de parâmetros por statement do PostgreSQL
+const maxStatementParams = 65535
+
+// maxRowsPerStatement é quantos produtos cabem em um INSERT
+var maxRowsPerStatement = maxStatementParams / len(productColumns)
+
+// setupPostgresTarget garante que a tabela de destino exista. O TRUNCATE só é
+// executado quando pedido explicitamente. Na retomada com INSERT simples, as
+// linhas acima do checkpoint são removidas, pois podem ter vindo de lotes
+// confirmados fora de ordem; com upsert elas são apenas regravadas.
+func setupPostgresTarget(ctx context.Context, db *sql.DB, truncate bool, resumeFrom *int, policy string) error {
+	fmt.Println("Preparando a tabela de destino 'products' no PostgreSQL...")
+	if err := createProductsTable(ctx, db); err != nil {
+		return err
+	}
+
+	if resumeFrom != nil {
+		if policy != ConflictError {
+			return nil
+		}
+
+		res, err := db.ExecContext(ctx, `DELETE FROM products WHERE id > $1`, *resumeFrom)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			fmt.Printf("%d linhas acima do checkpoint %d removidas.\n", n, *resumeFrom)
+		}
+		return nil
+	}
+
+	if !truncate {
+		return nil
+	}
+	fmt.Println("Truncando a tabela de destino 'products'...")
+	_, err := db.ExecContext(ctx, `TRUNCATE TABLE products;`)
+	return err
+}
+
+// createProductsTable cria a tabela de destino, se ainda não existir
+func createProductsTable(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS products (
+		id INT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		description TEXT,
+		price NUMERIC(10, 2) NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE
+	);`)
+	return err
+}
+
+// Limites das colunas de products, conferidos por validateProduct
+const (
+	maxNameLength = 255         // VARCHAR(255)
+	maxPrice      = 99999999.99 // NUMERIC(10, 2)
+)
+
+// validateProduct confere se o produto respeita as restrições da tabela de
+// destino, antecipando os erros que o PostgreSQL devolveria no INSERT
+func validateProduct(p models.Product) error {
+	switch {
+	case p.ID < math.MinInt32 || p.ID > math.MaxInt32:
+		return fmt.Errorf("produto %d: id fora do intervalo de INT", p.ID)
+	case utf8.RuneCountInString(p.Name) > maxNameLength:
+		return fmt.Errorf("produto %d: name com mais de %d caracteres", p.ID, maxNameLength)
+	case math.IsNaN(p.Price) || math.Abs(math.Round(p.Price*100)/100) > maxPrice:
+		return fmt.Errorf("produto %d: price %v não cabe em NUMERIC(10, 2)", p.ID, p.Price)
+	case strings.ContainsRune(p.Name, 0) || strings.ContainsRune(p.Description, 0):
+		return fmt.Errorf("produto %d: texto com caractere nulo (0x00)", p.ID)
+	case !utf8.ValidString(p.Name) || !utf8.ValidString(p.Description):
+		return fmt.Errorf("produto %d: texto com UTF-8 inválido", p.ID)
+	}
+	return nil
+}
+
+// execer é satisfeito tanto por *sql.DB quanto por *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertProducts grava os produtos com INSERTs de múltiplas linhas, um round
+// trip por statement, e retorna o total de linhas afetadas
+func insertProducts(ctx context.Context, db execer, products []models.Product, policy string) (int64, error) {
+	statement := func(rows int) string {
+		return insertStatement("products", productColumns, rows) + conflictClause(policy)
+	}
+	return insertRows(ctx, db, products, len(productColumns), statement, func(p models.Product) []interface{} {
+		return []interface{}{p.ID, p.Name, p.Description, p.Price, p.CreatedAt}
+	})
+}
+
+// insertRows grava as linhas com INSERTs de múltiplas linhas, quantas couberem
+// no limite de parâmetros, e retorna o total de linhas afetadas. statement
+// monta o INSERT de n linhas e values os width valores de cada linha.
+func insertRows[T any](ctx context.Context, db execer, rows []T, width int, statement func(n int) string, values func(T) []interface{}) (int64, error) {
+	perStatement := maxStatementParams / width
+	var affected int64
+	for start := 0; start < len(rows); start += perStatement {
+		chunk := rows[start:min(start+perStatement, len(rows))]
+
+		args := make([]interface{}, 0, len(chunk)*width)
+		for _, row := range chunk {
+			args = append(args, values(row)...)
+		}
+
+		res, err := db.ExecContext(ctx, statement(len(chunk)), args...)
+		if err != nil {
+			return affected, err
+		}
+		n, _ := res.RowsAffected()
+		affected += n
+	}
+	return affected, nil
+}
+
+// insertStatement monta um INSERT na tabela com placeholders para rows linhas.
+// table e columns devem vir já entre aspas quando necessário.
+func insertStatement(table string, columns []string, rows int) string {
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO " + table + " (")
+	sb.WriteString(strings.Join(columns, ", "))
+	sb.WriteString(") VALUES ")
+
+	n := 1
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('(')
+		for j := range columns {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprintf(&sb, "$%d", n)
+			n++
+		}
+		sb.WriteByte(')')
+	}
+	return sb.String()
+}
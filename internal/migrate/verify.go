@@ -0,0 +1,240 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"migration-go/internal/config"
+	"migration-go/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// productProjection limita a leitura da origem aos campos comparados
+var productProjection = bson.M{"product_id": 1, "name": 1, "description": 1, "price": 1, "created_at": 1}
+
+// VerifyReport é o resultado da comparação entre origem e destino. As listas
+// de IDs estão em ordem crescente e podem ser gravadas em JSON para o repair.
+type VerifyReport struct {
+	Source    string `json:"source"`
+	Documents int64  `json:"documents"`
+	Rows      int64  `json:"rows"`
+
+	// Missing são IDs presentes no MongoDB e ausentes no PostgreSQL; Extra, o
+	// contrário; Mismatched são IDs presentes nos dois lados com conteúdo diferente
+	Missing    []int `json:"missing"`
+	Extra      []int `json:"extra"`
+	Mismatched []int `json:"mismatched"`
+
+	// Invalid conta os documentos que não puderam ser decodificados
+	Invalid int64 `json:"invalid"`
+
+	Duration time.Duration `json:"-"`
+}
+
+// OK indica se origem e destino conferem
+func (r VerifyReport) OK() bool {
+	return r.Documents == r.Rows && r.Invalid == 0 &&
+		len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Mismatched) == 0
+}
+
+// String formata o resumo da verificação para exibição no terminal
+func (r VerifyReport) String() string {
+	return fmt.Sprintf("documentos=%d linhas=%d ausentes=%d extras=%d divergentes=%d inválidos=%d duração=%s",
+		r.Documents, r.Rows, len(r.Missing), len(r.Extra), len(r.Mismatched), r.Invalid, r.Duration)
+}
+
+// WriteFile grava o relatório em JSON
+func (r VerifyReport) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("erro ao gravar o relatório de verificação: %w", err)
+	}
+	return nil
+}
+
+// ReadVerifyReport carrega um relatório gravado por WriteFile
+func ReadVerifyReport(path string) (*VerifyReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o relatório de verificação: %w", err)
+	}
+	var r VerifyReport
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("relatório de verificação inválido em %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Verify compara a coleção de origem com a tabela products: contagens e um
+// hash por linha de (id, name, description, price, created_at). Os dois lados
+// são lidos ordenados por ID e comparados em um merge, sem carregar tudo na memória.
+func Verify(ctx context.Context, cfg *config.Config) (*VerifyReport, error) {
+	if err := wholeCollection(&cfg.App, "verify"); err != nil {
+		return nil, err
+	}
+
+	pgManager, mongoManager, disconnect, err := connect(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer disconnect()
+
+	startTime := time.Now()
+	report := &VerifyReport{Source: sourceKey(cfg)}
+
+	collection := mongoManager.GetCollection()
+	docs, err := collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao contar documentos no MongoDB: %w", err)
+	}
+	report.Documents = docs
+
+	if err := pgManager.GetDB().QueryRowContext(ctx, "SELECT COUNT(*) FROM products").Scan(&report.Rows); err != nil {
+		return nil, fmt.Errorf("erro ao contar linhas no PostgreSQL: %w", err)
+	}
+	fmt.Printf("MongoDB: %d documentos | PostgreSQL: %d linhas\n", report.Documents, report.Rows)
+
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().
+		SetSort(bson.D{{Key: "product_id", Value: 1}}).
+		SetProjection(productProjection))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar documentos no MongoDB: %w", err)
+	}
+	defer cursor.Close(context.WithoutCancel(ctx))
+
+	rows, err := pgManager.QueryProducts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar produtos no PostgreSQL: %w", err)
+	}
+	defer rows.Close()
+
+	if err := report.compare(ctx, cursor, rows); err != nil {
+		return nil, err
+	}
+
+	report.Duration = time.Since(startTime)
+	return report, nil
+}
+
+// compare percorre em merge os documentos e as linhas, ambos ordenados por ID,
+// acumulando no relatório os IDs ausentes, extras e divergentes
+func (r *VerifyReport) compare(ctx context.Context, cursor *mongo.Cursor, rows *sql.Rows) error {
+	nextDoc := func() (models.Product, bool, error) {
+		for cursor.Next(ctx) {
+			var p models.Product
+			if err := cursor.Decode(&p); err != nil {
+				log.Printf("Documento inválido no MongoDB: %v", err)
+				r.Invalid++
+				continue
+			}
+			return p, true, nil
+		}
+		return models.Product{}, false, cursor.Err()
+	}
+	nextRow := func() (models.Product, bool, error) {
+		if !rows.Next() {
+			return models.Product{}, false, rows.Err()
+		}
+		p, err := scanProduct(rows)
+		return p, err == nil, err
+	}
+
+	doc, hasDoc, err := nextDoc()
+	if err != nil {
+		return err
+	}
+	row, hasRow, err := nextRow()
+	if err != nil {
+		return err
+	}
+	for hasDoc || hasRow {
+		switch {
+		case hasDoc && (!hasRow || doc.ID < row.ID):
+			r.Missing = append(r.Missing, doc.ID)
+			doc, hasDoc, err = nextDoc()
+		case hasRow && (!hasDoc || row.ID < doc.ID):
+			r.Extra = append(r.Extra, row.ID)
+			row, hasRow, err = nextRow()
+		default:
+			if productHash(doc) != productHash(row) {
+				r.Mismatched = append(r.Mismatched, doc.ID)
+			}
+			if doc, hasDoc, err = nextDoc(); err == nil {
+				row, hasRow, err = nextRow()
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("erro durante a comparação: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// productHash calcula o hash de conteúdo de um produto. Os campos são
+// normalizados para a precisão comum aos dois bancos: preço com 2 casas
+// (NUMERIC(10, 2), ver priceText) e created_at em UTC com milissegundos (BSON date).
+func productHash(p models.Product) [sha256.Size]byte {
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(p.ID))
+	b.WriteByte(0x1f)
+	b.WriteString(p.Name)
+	b.WriteByte(0x1f)
+	b.WriteString(p.Description)
+	b.WriteByte(0x1f)
+	b.WriteString(priceText(p.Price))
+	b.WriteByte(0x1f)
+	if !p.CreatedAt.IsZero() {
+		b.WriteString(p.CreatedAt.UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano))
+	}
+	return sha256.Sum256([]byte(b.String()))
+}
+
+// priceText formata o preço como o PostgreSQL o guarda em NUMERIC(10, 2). O
+// driver envia o float64 na menor representação decimal ('f', -1), e o
+// PostgreSQL arredonda esse texto para 2 casas com empate para longe do zero;
+// FormatFloat(p, 'f', 2, 64) arredondaria o valor binário (0.125 -> "0.12",
+// 1.005 -> "1.00"), divergindo do banco.
+func priceText(price float64) string {
+	if math.IsNaN(price) || math.IsInf(price, 0) {
+		return strconv.FormatFloat(price, 'f', -1, 64)
+	}
+
+	s := strconv.FormatFloat(math.Abs(price), 'f', -1, 64)
+	intPart, frac, _ := strings.Cut(s, ".")
+	digits := []byte(intPart + (frac + "00")[:2])
+	if len(frac) > 2 && frac[2] >= '5' {
+		i := len(digits) - 1
+		for ; i >= 0 && digits[i] == '9'; i-- {
+			digits[i] = '0'
+		}
+		if i < 0 {
+			digits = append([]byte{'1'}, digits...)
+		} else {
+			digits[i]++
+		}
+	}
+
+	n := len(digits) - 2
+	out := string(digits[:n]) + "." + string(digits[n:])
+	// NUMERIC não tem zero negativo
+	if price < 0 && strings.Trim(string(digits), "0") != "" {
+		out = "-" + out
+	}
+	return out
+}
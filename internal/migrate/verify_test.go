@@ -0,0 +1,36 @@
+package migrate
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPriceText(t *testing.T) {
+	tests := []struct {
+		price float64
+		want  string
+	}{
+		{0, "0.00"},
+		{19.9, "19.90"},
+		{100, "100.00"},
+		{12.34, "12.34"},
+		// Empates no texto decimal sobem, mesmo quando o binário está abaixo
+		{0.125, "0.13"},
+		{1.005, "1.01"},
+		{2.675, "2.68"},
+		{10.245, "10.25"},
+		{0.124, "0.12"},
+		{9.995, "10.00"},
+		{99999999.995, "100000000.00"},
+		{-0.125, "-0.13"},
+		{-2.675, "-2.68"},
+		{-0.001, "0.00"},
+		{1e-7, "0.00"},
+		{math.Copysign(0, -1), "0.00"},
+	}
+	for _, tt := range tests {
+		if got := priceText(tt.price); got != tt.want {
+			t.Errorf("priceText(%v) = %q, quer %q", tt.price, got, tt.want)
+		}
+	}
+}
@@ -9,6 +9,9 @@ type Product struct {
 	Description string    `bson:"description"`
 	Price       float64   `bson:"price"`
 	CreatedAt   time.Time `bson:"created_at"`
+
+	// UpdatedAt é opcional na origem e só é usado pela sincronização incremental
+	UpdatedAt time.Time `bson:"updated_at,omitempty"`
 }
 
 // LargeProduct representa a estrutura de um produto com campos grandes (para testes de memória)
@@ -0,0 +1,258 @@
+// Package schema infere, a partir de uma amostra de documentos do MongoDB, uma
+// proposta de tabela no PostgreSQL.
+package schema
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Field acumula o que foi observado de um campo de primeiro nível na amostra
+type Field struct {
+	Name    string
+	Present int64
+	Nulls   int64
+	Types   map[bsontype.Type]int64
+
+	// MaxLength é o maior tamanho, em caracteres, visto nos valores string
+	MaxLength int
+}
+
+// Schema é o resultado da inferência sobre uma amostra da coleção
+type Schema struct {
+	Collection string
+	Sampled    int64
+	Fields     []*Field
+}
+
+// Infer lê até sample documentos aleatórios da coleção ($sample) e registra,
+// para cada campo de primeiro nível, em quantos documentos ele aparece e com
+// quais tipos BSON. Documentos e arrays aninhados viram colunas JSONB.
+func Infer(ctx context.Context, collection *mongo.Collection, sample int) (*Schema, error) {
+	pipeline := mongo.Pipeline{{{Key: "$sample", Value: bson.M{"size": max(sample, 1)}}}}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao amostrar a coleção %s: %w", collection.Name(), err)
+	}
+	defer cursor.Close(context.WithoutCancel(ctx))
+
+	s := &Schema{Collection: collection.Name()}
+	fields := map[string]*Field{}
+	for cursor.Next(ctx) {
+		elems, err := cursor.Current.Elements()
+		if err != nil {
+			return nil, fmt.Errorf("documento inválido na amostra: %w", err)
+		}
+		s.Sampled++
+
+		for _, e := range elems {
+			f, ok := fields[e.Key()]
+			if !ok {
+				f = &Field{Name: e.Key(), Types: map[bsontype.Type]int64{}}
+				fields[e.Key()] = f
+				s.Fields = append(s.Fields, f)
+			}
+
+			v := e.Value()
+			f.Present++
+			if v.Type == bson.TypeNull || v.Type == bson.TypeUndefined {
+				f.Nulls++
+				continue
+			}
+			f.Types[v.Type]++
+			if str, ok := v.StringValueOK(); ok {
+				f.MaxLength = max(f.MaxLength, len([]rune(str)))
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler a amostra: %w", err)
+	}
+
+	// _id primeiro; os demais na ordem em que apareceram
+	slices.SortStableFunc(s.Fields, func(a, b *Field) int {
+		switch {
+		case a.Name == "_id" && b.Name != "_id":
+			return -1
+		case b.Name == "_id" && a.Name != "_id":
+			return 1
+		}
+		return 0
+	})
+	return s, nil
+}
+
+// Nullable indica se a coluna precisa aceitar NULL: o campo faltou em algum
+// documento da amostra ou apareceu com valor nulo
+func (s *Schema) Nullable(f *Field) bool {
+	return f.Present < s.Sampled || f.Nulls > 0
+}
+
+// Conflict descreve um campo com tipos BSON incompatíveis entre si
+type Conflict struct {
+	Field  string
+	Types  map[bsontype.Type]int64
+	Chosen string
+}
+
+// String formata o conflito para exibição no terminal
+func (c Conflict) String() string {
+	types := make([]string, 0, len(c.Types))
+	for t, n := range c.Types {
+		types = append(types, fmt.Sprintf("%s=%d", t, n))
+	}
+	slices.Sort(types)
+	return fmt.Sprintf("%s: %s -> %s", c.Field, strings.Join(types, ", "), c.Chosen)
+}
+
+// Conflicts lista os campos cujos tipos não puderam ser unificados sem perda
+func (s *Schema) Conflicts() []Conflict {
+	var out []Conflict
+	for _, f := range s.Fields {
+		if pgType, ok := columnType(f); !ok {
+			out = append(out, Conflict{Field: f.Name, Types: f.Types, Chosen: pgType})
+		}
+	}
+	return out
+}
+
+// DDL monta o CREATE TABLE proposto para a tabela informada. _id vira a chave
+// primária; os nomes de colunas são convertidos para snake_case. Retorna erro
+// se dois campos resultarem na mesma coluna.
+func (s *Schema) DDL(table string) (string, error) {
+	fields := map[string]string{}
+	for _, f := range s.Fields {
+		column := ColumnName(f.Name)
+		if other, ok := fields[column]; ok {
+			return "", fmt.Errorf("os campos %q e %q resultam na mesma coluna %q", other, f.Name, column)
+		}
+		fields[column] = f.Name
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CREATE TABLE IF NOT EXISTS %s (\n", pq.QuoteIdentifier(table))
+	for i, f := range s.Fields {
+		pgType, _ := columnType(f)
+		fmt.Fprintf(&sb, "\t%s %s", pq.QuoteIdentifier(ColumnName(f.Name)), pgType)
+		switch {
+		case f.Name == "_id":
+			sb.WriteString(" PRIMARY KEY")
+		case !s.Nullable(f):
+			sb.WriteString(" NOT NULL")
+		}
+		if i < len(s.Fields)-1 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, " -- presente em %d/%d", f.Present, s.Sampled)
+		if f.Nulls > 0 {
+			fmt.Fprintf(&sb, ", nulo em %d", f.Nulls)
+		}
+		sb.WriteByte('\n')
+	}
+	sb.WriteString(");\n")
+	return sb.String(), nil
+}
+
+// ColumnName converte o nome de um campo do MongoDB em nome de coluna:
+// camelCase vira snake_case e _id vira source_id
+func ColumnName(field string) string {
+	if field == "_id" {
+		return "source_id"
+	}
+
+	var sb strings.Builder
+	runes := []rune(field)
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
+
+// columnType escolhe o tipo PostgreSQL para os tipos BSON observados. Tipos
+// numéricos e de data são alargados entre si; qualquer outra mistura é um
+// conflito (ok=false), resolvido com JSONB para não perder valores.
+func columnType(f *Field) (pgType string, ok bool) {
+	families := map[string]bool{}
+	for t := range f.Types {
+		families[family(t)] = true
+	}
+	if families["integer"] && families["numeric"] {
+		delete(families, "integer")
+	}
+
+	switch len(families) {
+	case 0:
+		// Só nulos na amostra: não há como saber o tipo
+		return "JSONB", true
+	case 1:
+	default:
+		return "JSONB", false
+	}
+
+	has := func(t bsontype.Type) bool { return f.Types[t] > 0 }
+	switch {
+	case families["integer"] && !has(bson.TypeInt64):
+		return "INTEGER", true
+	case families["integer"]:
+		return "BIGINT", true
+	case families["numeric"] && has(bson.TypeDecimal128):
+		return "NUMERIC", true
+	case families["numeric"]:
+		return "DOUBLE PRECISION", true
+	case families["text"] && f.MaxLength > 0 && f.MaxLength <= 255:
+		return "VARCHAR(255)", true
+	case families["text"]:
+		return "TEXT", true
+	case families["boolean"]:
+		return "BOOLEAN", true
+	case families["timestamp"]:
+		return "TIMESTAMP WITH TIME ZONE", true
+	case families["objectid"]:
+		return "CHAR(24)", true
+	case families["binary"]:
+		return "BYTEA", true
+	default:
+		return "JSONB", true
+	}
+}
+
+// family agrupa os tipos BSON que podem compartilhar uma coluna. Inteiros
+// misturados com double ou decimal são alargados por columnType para numeric.
+func family(t bsontype.Type) string {
+	switch t {
+	case bson.TypeInt32, bson.TypeInt64:
+		return "integer"
+	case bson.TypeDouble, bson.TypeDecimal128:
+		return "numeric"
+	case bson.TypeString, bson.TypeSymbol:
+		return "text"
+	case bson.TypeBoolean:
+		return "boolean"
+	case bson.TypeDateTime, bson.TypeTimestamp:
+		return "timestamp"
+	case bson.TypeObjectID:
+		return "objectid"
+	case bson.TypeBinary:
+		return "binary"
+	default:
+		return "document"
+	}
+}
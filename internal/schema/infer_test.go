@@ -0,0 +1,132 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"_id", "source_id"},
+		{"name", "name"},
+		{"createdAt", "created_at"},
+		{"foo_bar", "foo_bar"},
+		{"fooBar", "foo_bar"},
+		{"sku2Code", "sku2_code"},
+		{"HTTPStatus", "httpstatus"},
+		{"price-brl", "price_brl"},
+		{"a.b c", "a_b_c"},
+		{"preçoFinal", "preço_final"},
+	}
+	for _, tt := range tests {
+		if got := ColumnName(tt.field); got != tt.want {
+			t.Errorf("ColumnName(%q) = %q, quer %q", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestColumnType(t *testing.T) {
+	tests := []struct {
+		name      string
+		types     map[bsontype.Type]int64
+		maxLength int
+		want      string
+		wantOK    bool
+	}{
+		{"só nulos", nil, 0, "JSONB", true},
+		{"int32", map[bsontype.Type]int64{bson.TypeInt32: 3}, 0, "INTEGER", true},
+		{"int32 e int64", map[bsontype.Type]int64{bson.TypeInt32: 3, bson.TypeInt64: 1}, 0, "BIGINT", true},
+		{"inteiro e double", map[bsontype.Type]int64{bson.TypeInt32: 3, bson.TypeDouble: 1}, 0, "DOUBLE PRECISION", true},
+		{"decimal128", map[bsontype.Type]int64{bson.TypeDecimal128: 1, bson.TypeInt64: 1}, 0, "NUMERIC", true},
+		{"texto curto", map[bsontype.Type]int64{bson.TypeString: 2}, 255, "VARCHAR(255)", true},
+		{"texto longo", map[bsontype.Type]int64{bson.TypeString: 2}, 256, "TEXT", true},
+		{"texto vazio", map[bsontype.Type]int64{bson.TypeString: 2}, 0, "TEXT", true},
+		{"booleano", map[bsontype.Type]int64{bson.TypeBoolean: 1}, 0, "BOOLEAN", true},
+		{"datas", map[bsontype.Type]int64{bson.TypeDateTime: 1, bson.TypeTimestamp: 1}, 0, "TIMESTAMP WITH TIME ZONE", true},
+		{"objectid", map[bsontype.Type]int64{bson.TypeObjectID: 1}, 0, "CHAR(24)", true},
+		{"binário", map[bsontype.Type]int64{bson.TypeBinary: 1}, 0, "BYTEA", true},
+		{"documento e array", map[bsontype.Type]int64{bson.TypeEmbeddedDocument: 1, bson.TypeArray: 1}, 0, "JSONB", true},
+		{"texto e número", map[bsontype.Type]int64{bson.TypeString: 1, bson.TypeInt32: 1}, 3, "JSONB", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Field{Name: "f", Types: tt.types, MaxLength: tt.maxLength}
+			got, ok := columnType(f)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("columnType = (%q, %t), quer (%q, %t)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNullable(t *testing.T) {
+	s := &Schema{Sampled: 10}
+	tests := []struct {
+		name  string
+		field Field
+		want  bool
+	}{
+		{"presente e não nulo em toda a amostra", Field{Present: 10}, false},
+		{"ausente em algum documento", Field{Present: 9}, true},
+		{"nulo em algum documento", Field{Present: 10, Nulls: 1}, true},
+	}
+	for _, tt := range tests {
+		if got := s.Nullable(&tt.field); got != tt.want {
+			t.Errorf("%s: Nullable = %t, quer %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDDL(t *testing.T) {
+	field := func(name string, t bsontype.Type) *Field {
+		return &Field{Name: name, Present: 2, Types: map[bsontype.Type]int64{t: 2}}
+	}
+
+	s := &Schema{Sampled: 2, Fields: []*Field{
+		field("_id", bson.TypeObjectID),
+		field("createdAt", bson.TypeDateTime),
+		{Name: "note", Present: 1, Types: map[bsontype.Type]int64{bson.TypeString: 1}, MaxLength: 10},
+	}}
+	ddl, err := s.DDL("orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`CREATE TABLE IF NOT EXISTS "orders" (`,
+		`"source_id" CHAR(24) PRIMARY KEY,`,
+		`"created_at" TIMESTAMP WITH TIME ZONE NOT NULL,`,
+		`"note" VARCHAR(255) -- presente em 1/2`,
+	} {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("DDL sem %q:\n%s", want, ddl)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		fields []string
+		want   string
+	}{
+		{"camelCase e snake_case", []string{"fooBar", "foo_bar"}, `os campos "fooBar" e "foo_bar" resultam na mesma coluna "foo_bar"`},
+		{"_id e source_id", []string{"_id", "source_id"}, `os campos "_id" e "source_id" resultam na mesma coluna "source_id"`},
+		{"pontuação", []string{"a-b", "a.b"}, `os campos "a-b" e "a.b" resultam na mesma coluna "a_b"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Schema{Sampled: 2}
+			for _, name := range tt.fields {
+				s.Fields = append(s.Fields, field(name, bson.TypeString))
+			}
+			_, err := s.DDL("t")
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("erro = %v, quer %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Rename move o valor de from para to. Registros sem from não são alterados.
+func Rename(from, to string) Transformer {
+	return Func(func(r Record) ([]Record, error) {
+		if v, ok := get(r, from); ok {
+			del(r, from)
+			if err := set(r, to, v); err != nil {
+				return nil, err
+			}
+		}
+		return []Record{r}, nil
+	})
+}
+
+// Drop remove os campos informados
+func Drop(fields ...string) Transformer {
+	return Func(func(r Record) ([]Record, error) {
+		for _, f := range fields {
+			del(r, f)
+		}
+		return []Record{r}, nil
+	})
+}
+
+// Default preenche o campo com value quando ele está ausente ou nulo
+func Default(field string, value interface{}) Transformer {
+	return Func(func(r Record) ([]Record, error) {
+		if v, ok := get(r, field); !ok || v == nil {
+			if err := set(r, field, value); err != nil {
+				return nil, err
+			}
+		}
+		return []Record{r}, nil
+	})
+}
+
+// Compute grava em field o valor calculado por fn a partir do registro
+func Compute(field string, fn func(r Record) (interface{}, error)) Transformer {
+	return Func(func(r Record) ([]Record, error) {
+		v, err := fn(r)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao calcular %s: %w", field, err)
+		}
+		if err := set(r, field, v); err != nil {
+			return nil, err
+		}
+		return []Record{r}, nil
+	})
+}
+
+// Filter descarta os registros para os quais drop retorna true
+func Filter(drop func(r Record) bool) Transformer {
+	return Func(func(r Record) ([]Record, error) {
+		if drop(r) {
+			return nil, nil
+		}
+		return []Record{r}, nil
+	})
+}
+
+// get lê o valor em um caminho com pontos
+func get(r Record, path string) (interface{}, bool) {
+	parts := strings.Split(path, ".")
+	cur := r
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := cur[p].(Record)
+		if !ok {
+			return nil, false
+		}
+		cur = next
+	}
+	v, ok := cur[parts[len(parts)-1]]
+	return v, ok
+}
+
+// set grava o valor em um caminho com pontos, criando os níveis intermediários
+func set(r Record, path string, v interface{}) error {
+	parts := strings.Split(path, ".")
+	cur := r
+	for _, p := range parts[:len(parts)-1] {
+		switch next := cur[p].(type) {
+		case Record:
+			cur = next
+		case nil:
+			child := Record{}
+			cur[p] = child
+			cur = child
+		default:
+			return errors.New("não é possível gravar em " + path + ": " + p + " não é um documento")
+		}
+	}
+	cur[parts[len(parts)-1]] = v
+	return nil
+}
+
+// del remove o valor em um caminho com pontos, se existir
+func del(r Record, path string) {
+	parts := strings.Split(path, ".")
+	cur := r
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := cur[p].(Record)
+		if !ok {
+			return
+		}
+		cur = next
+	}
+	delete(cur, parts[len(parts)-1])
+}
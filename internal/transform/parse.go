@@ -0,0 +1,180 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Parse monta uma cadeia a partir da especificação textual usada em
+// TRANSFORMS/--transform. Os passos são separados por ";":
+//
+//	rename:origem=destino
+//	drop:campo1,campo2
+//	default:campo=valor             valor em JSON (0, true, "x") ou texto
+//	compute:campo=funcao(args)      lower, upper, trim, concat ou now
+//	filter:campo=valor              descarta registros com campo igual a valor
+//	filter:campo!=valor             descarta registros com campo diferente de valor
+//	filter:!campo                   descarta registros sem o campo (ou nulo)
+//
+// Argumentos de compute são caminhos de campos ou textos entre aspas simples,
+// ex.: compute:label=concat(name,' - ',sku).
+func Parse(spec string) (Chain, error) {
+	var chain Chain
+	for _, step := range strings.Split(spec, ";") {
+		step = strings.TrimSpace(step)
+		if step == "" {
+			continue
+		}
+		kind, arg, _ := strings.Cut(step, ":")
+
+		var t Transformer
+		var err error
+		switch kind {
+		case "rename":
+			from, to, ok := strings.Cut(arg, "=")
+			if !ok || from == "" || to == "" {
+				err = fmt.Errorf("use rename:origem=destino")
+			}
+			t = Rename(from, to)
+		case "drop":
+			t = Drop(strings.Split(arg, ",")...)
+		case "default":
+			field, value, ok := strings.Cut(arg, "=")
+			if !ok || field == "" {
+				err = fmt.Errorf("use default:campo=valor")
+			}
+			t = Default(field, literal(value))
+		case "compute":
+			t, err = parseCompute(arg)
+		case "filter":
+			t, err = parseFilter(arg)
+		default:
+			err = fmt.Errorf("transformação desconhecida (disponíveis: rename, drop, default, compute, filter)")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("transformação inválida %q: %w", step, err)
+		}
+		chain = append(chain, t)
+	}
+	return chain, nil
+}
+
+// parseCompute interpreta campo=funcao(args)
+func parseCompute(arg string) (Transformer, error) {
+	field, expr, ok := strings.Cut(arg, "=")
+	name, rest, hasParen := strings.Cut(expr, "(")
+	if !ok || field == "" || !hasParen || !strings.HasSuffix(rest, ")") {
+		return nil, fmt.Errorf("use compute:campo=funcao(args)")
+	}
+	args := splitArgs(strings.TrimSuffix(rest, ")"))
+
+	// value resolve um argumento: texto entre aspas simples ou caminho de campo
+	value := func(r Record, a string) interface{} {
+		if len(a) >= 2 && strings.HasPrefix(a, "'") && strings.HasSuffix(a, "'") {
+			return a[1 : len(a)-1]
+		}
+		v, _ := get(r, a)
+		return v
+	}
+	text := func(r Record, a string) string {
+		v := value(r, a)
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprint(v)
+	}
+
+	unary := func(fn func(string) string) (Transformer, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s espera um argumento", name)
+		}
+		return Compute(field, func(r Record) (interface{}, error) {
+			return fn(text(r, args[0])), nil
+		}), nil
+	}
+
+	switch name {
+	case "lower":
+		return unary(strings.ToLower)
+	case "upper":
+		return unary(strings.ToUpper)
+	case "trim":
+		return unary(strings.TrimSpace)
+	case "concat":
+		return Compute(field, func(r Record) (interface{}, error) {
+			var sb strings.Builder
+			for _, a := range args {
+				sb.WriteString(text(r, a))
+			}
+			return sb.String(), nil
+		}), nil
+	case "now":
+		return Compute(field, func(r Record) (interface{}, error) {
+			return time.Now().UTC(), nil
+		}), nil
+	default:
+		return nil, fmt.Errorf("função desconhecida %q (disponíveis: lower, upper, trim, concat, now)", name)
+	}
+}
+
+// parseFilter interpreta campo=valor, campo!=valor ou !campo
+func parseFilter(arg string) (Transformer, error) {
+	// !campo não tem valor; "!a=b" não é a negação de "a=b" e é recusado
+	if field, ok := strings.CutPrefix(arg, "!"); ok {
+		if field == "" || strings.Contains(field, "=") {
+			return nil, fmt.Errorf("use filter:campo=valor, filter:campo!=valor ou filter:!campo")
+		}
+		return Filter(func(r Record) bool {
+			v, ok := get(r, field)
+			return !ok || v == nil
+		}), nil
+	}
+	if field, value, ok := strings.Cut(arg, "!="); ok && field != "" {
+		return Filter(func(r Record) bool {
+			v, _ := get(r, field)
+			return fmt.Sprint(v) != value
+		}), nil
+	}
+	if field, value, ok := strings.Cut(arg, "="); ok && field != "" {
+		return Filter(func(r Record) bool {
+			v, ok := get(r, field)
+			return ok && fmt.Sprint(v) == value
+		}), nil
+	}
+	return nil, fmt.Errorf("use filter:campo=valor, filter:campo!=valor ou filter:!campo")
+}
+
+// literal interpreta um valor de default como JSON; se não for JSON válido,
+// usa o texto como está
+func literal(s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	if f, ok := v.(float64); ok && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int64(f)
+	}
+	return v
+}
+
+// splitArgs separa argumentos por vírgula, respeitando textos entre aspas simples
+func splitArgs(s string) []string {
+	var out []string
+	quoted, start := false, 0
+	for i, r := range s {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			out = append(out, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(s[start:]); rest != "" || len(out) > 0 {
+		out = append(out, rest)
+	}
+	return out
+}
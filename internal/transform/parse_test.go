@@ -0,0 +1,111 @@
+package transform
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		steps   int
+		wantErr bool
+	}{
+		{"", 0, false},
+		{" ; ", 0, false},
+		{"rename:sku=product_id; drop:a,b ;default:x=1", 3, false},
+		{"compute:label=concat(name,' - ',sku)", 1, false},
+		{"filter:a=b;filter:a!=b;filter:!a", 3, false},
+		{"rename:sku", 0, true},
+		{"rename:=product_id", 0, true},
+		{"default:=1", 0, true},
+		{"compute:label=lower", 0, true},
+		{"compute:label=lower(a,b)", 0, true},
+		{"compute:label=reverse(a)", 0, true},
+		{"filter:", 0, true},
+		{"filter:!", 0, true},
+		{"filter:!a=b", 0, true},
+		{"filter:=b", 0, true},
+		{"split:a", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			chain, err := Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("erro = %v, quer erro %t", err, tt.wantErr)
+			}
+			if len(chain) != tt.steps {
+				t.Errorf("%d passos, quer %d", len(chain), tt.steps)
+			}
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		spec string
+		rec  Record
+		keep bool
+	}{
+		{"a=b", Record{"a": "b"}, false},
+		{"a=b", Record{"a": "c"}, true},
+		{"a=b", Record{}, true},
+		{"a=7", Record{"a": int32(7)}, false},
+		{"a!=b", Record{"a": "b"}, true},
+		{"a!=b", Record{"a": "c"}, false},
+		{"a!=b", Record{}, false},
+		{"!a", Record{"a": "b"}, true},
+		{"!a", Record{"a": nil}, false},
+		{"!a", Record{}, false},
+		{"!a.b", Record{"a": Record{"b": 1}}, true},
+		{"!a.b", Record{"a": Record{}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			f, err := parseFilter(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := f.Transform(tt.rec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if keep := len(out) == 1; keep != tt.keep {
+				t.Errorf("%v mantido = %t, quer %t", tt.rec, keep, tt.keep)
+			}
+		})
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"name", []string{"name"}},
+		{"name, sku", []string{"name", "sku"}},
+		{"name,' - , ',sku", []string{"name", "' - , '", "sku"}},
+		{"'a,b'", []string{"'a,b'"}},
+		{"name,", []string{"name", ""}},
+	}
+	for _, tt := range tests {
+		if got := splitArgs(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, quer %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseConcat(t *testing.T) {
+	chain, err := Parse("compute:label=concat(name,' - , ',sku)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := chain.Transform(Record{"name": "Caneta", "sku": 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out[0]["label"]; got != "Caneta - , 7" {
+		t.Errorf("label = %q, quer %q", got, "Caneta - , 7")
+	}
+}
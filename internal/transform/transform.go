@@ -0,0 +1,76 @@
+// Package transform define transformações por registro aplicadas entre a
+// leitura do MongoDB e a gravação no PostgreSQL.
+package transform
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Record é um documento em transformação. Documentos aninhados também são
+// Record, de modo que caminhos com pontos (ex.: address.city) podem ser usados.
+type Record = bson.M
+
+// Transformer recebe um registro e devolve zero (descarta), um ou vários
+// registros, ou um erro que rejeita o documento
+type Transformer interface {
+	Transform(r Record) ([]Record, error)
+}
+
+// Func adapta uma função comum para Transformer
+type Func func(r Record) ([]Record, error)
+
+func (f Func) Transform(r Record) ([]Record, error) {
+	return f(r)
+}
+
+// Chain aplica os transformers em sequência: cada registro produzido por um
+// passo é entregue ao seguinte
+type Chain []Transformer
+
+func (c Chain) Transform(r Record) ([]Record, error) {
+	records := []Record{r}
+	for _, t := range c {
+		var next []Record
+		for _, rec := range records {
+			out, err := t.Transform(rec)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, out...)
+		}
+		if len(next) == 0 {
+			return nil, nil
+		}
+		records = next
+	}
+	return records, nil
+}
+
+// ApplyRaw aplica a cadeia a um documento BSON e devolve os documentos
+// resultantes. Sem transformers, o documento original é devolvido sem cópia.
+func (c Chain) ApplyRaw(doc bson.Raw) ([]bson.Raw, error) {
+	if len(c) == 0 {
+		return []bson.Raw{doc}, nil
+	}
+
+	var r Record
+	if err := bson.Unmarshal(doc, &r); err != nil {
+		return nil, err
+	}
+	records, err := c.Transform(r)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]bson.Raw, len(records))
+	for i, rec := range records {
+		raw, err := bson.Marshal(rec)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao serializar o registro transformado: %w", err)
+		}
+		out[i] = raw
+	}
+	return out, nil
+}